@@ -0,0 +1,328 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	ACHIEVEMENTS_FILE       = "assets/achievements.json"
+	ACHIEVEMENTS_STATE_FILE = "achievements.dat"
+)
+
+// Stats achievements can be defined on
+const (
+	StatKills             = "kills"
+	StatShots             = "shots"
+	StatGrenades          = "grenades"
+	StatPickups           = "pickups"
+	StatReloads           = "reloads"
+	StatLevel             = "level"
+	StatScore             = "score"
+	StatTimeWithoutDamage = "timeWithoutDamage"
+)
+
+// Whether an achievement counts within one run or over every run
+const (
+	ScopeRun      = "run"
+	ScopeLifetime = "lifetime"
+)
+
+var (
+	toastDuration   float32 = 3.0 // How long an unlock toast stays on screen
+	toastSlideTime  float32 = 0.3 // Time to slide in and out
+	toastWidth      int32   = 380
+	toastHeight     int32   = 70
+	toastMarginLeft int32   = 20
+)
+
+// AchievementDef is one achievement as read from the data file
+type AchievementDef struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Stat        string  `json:"stat"`
+	Target      float64 `json:"target"`
+	Scope       string  `json:"scope"`
+	Weapon      string  `json:"weapon,omitempty"`     // Only count the stat for this weapon
+	OnlyWeapon  string  `json:"onlyWeapon,omitempty"` // The run fails it as soon as another weapon is used
+}
+
+// Key the stat is counted under, weapon specific stats get their own key
+func (def *AchievementDef) key() string {
+	if def.Weapon != "" {
+		return def.Stat + ":" + def.Weapon
+	}
+	return def.Stat
+}
+
+// achievementState is what's stored on disk between runs
+type achievementState struct {
+	Unlocked map[string]string  `json:"unlocked"` // Achievement id to unlock date
+	Lifetime map[string]float64 `json:"lifetime"` // Stat counters over every run
+}
+
+// AchievementToast is an unlock notification on screen
+type AchievementToast struct {
+	def  *AchievementDef
+	life float32
+}
+
+// AchievementSystem counts gameplay events and unlocks achievements
+type AchievementSystem struct {
+	defs        []AchievementDef
+	state       achievementState
+	run         map[string]float64 // Stat counters of the current run
+	weaponsUsed map[string]bool    // Guns fired this run, for the only weapon achievements
+	toasts      []AchievementToast
+	dirty       bool // Lifetime counters changed since the last save
+}
+
+// Shared achievement system
+var achievements *AchievementSystem
+
+// Load the definitions and the unlocks of previous runs
+func InitAchievements() {
+	achievements = &AchievementSystem{
+		state: achievementState{
+			Unlocked: make(map[string]string),
+			Lifetime: make(map[string]float64),
+		},
+		run:         make(map[string]float64),
+		weaponsUsed: make(map[string]bool),
+	}
+
+	data, err := os.ReadFile(ACHIEVEMENTS_FILE)
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "Failed to load achievements from %s! Achievements are disabled.", ACHIEVEMENTS_FILE)
+		return
+	}
+	if err := json.Unmarshal(data, &achievements.defs); err != nil {
+		rl.TraceLog(rl.LogWarning, "Failed to parse %s: %s", ACHIEVEMENTS_FILE, err.Error())
+		return
+	}
+
+	// No state file just means nothing is unlocked yet
+	data, err = os.ReadFile(saveFilePath(ACHIEVEMENTS_STATE_FILE))
+	if err != nil {
+		return
+	}
+	var state achievementState
+	if err := json.Unmarshal(data, &state); err != nil {
+		rl.TraceLog(rl.LogWarning, "Achievement progress is damaged, starting over: %s", err.Error())
+		return
+	}
+	if state.Unlocked != nil {
+		achievements.state.Unlocked = state.Unlocked
+	}
+	if state.Lifetime != nil {
+		achievements.state.Lifetime = state.Lifetime
+	}
+}
+
+// Write the unlocks and lifetime counters to disk
+func (as *AchievementSystem) Save() {
+	data, err := json.Marshal(as.state)
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "Failed to save achievements: %s", err.Error())
+		return
+	}
+
+	if err := writeFileAtomic(saveFilePath(ACHIEVEMENTS_STATE_FILE), data); err != nil {
+		rl.TraceLog(rl.LogWarning, "Failed to save achievements: %s", err.Error())
+		return
+	}
+	as.dirty = false
+}
+
+// Count achievement progress from gameplay events
+func subscribeAchievements(bus *EventBus) {
+	Subscribe(bus, func(e ShotFired) {
+		achievements.weaponsUsed[e.Weapon] = true
+		achievements.Record(StatShots, e.Weapon, float64(e.Count))
+	})
+	Subscribe(bus, func(e GrenadeThrown) {
+		// Grenades aren't shots and don't count against the only weapon achievements
+		achievements.Record(StatGrenades, "", 1)
+	})
+	Subscribe(bus, func(e EnemyKilled) {
+		achievements.Record(StatKills, e.Weapon, 1)
+	})
+	Subscribe(bus, func(e DamageTaken) {
+		achievements.PlayerDamaged()
+	})
+	Subscribe(bus, func(e PickupCollected) {
+		// Only weapons, ammo and grenades, coins drop from almost every kill
+		switch e.Kind {
+		case "weapon", "ammo", "grenade":
+			achievements.Record(StatPickups, "", 1)
+		}
+	})
+	Subscribe(bus, func(e ReloadStarted) {
+		achievements.Record(StatReloads, e.Weapon, 1)
+	})
+	Subscribe(bus, func(e LevelCompleted) {
+		achievements.RecordBest(StatLevel, float64(e.Level+1))
+	})
+}
+
+// Forget the counters of the last run
+func (as *AchievementSystem) StartRun() {
+	as.run = make(map[string]float64)
+	as.weaponsUsed = make(map[string]bool)
+}
+
+// Store the lifetime counters once the run is over
+func (as *AchievementSystem) EndRun() {
+	if as.dirty {
+		as.Save()
+	}
+}
+
+// Add to a stat, weapon can be empty for stats that don't depend on one
+func (as *AchievementSystem) Record(stat, weapon string, amount float64) {
+	as.run[stat] += amount
+	as.state.Lifetime[stat] += amount
+	if weapon != "" {
+		as.run[stat+":"+weapon] += amount
+		as.state.Lifetime[stat+":"+weapon] += amount
+	}
+	as.dirty = true
+
+	as.check(stat)
+}
+
+// Raise a stat that is a best value rather than a count (level, score)
+func (as *AchievementSystem) RecordBest(stat string, value float64) {
+	if value <= as.run[stat] {
+		return
+	}
+
+	as.run[stat] = value
+	if value > as.state.Lifetime[stat] {
+		as.state.Lifetime[stat] = value
+		as.dirty = true
+	}
+
+	as.check(stat)
+}
+
+// Taking damage resets the time without damage
+func (as *AchievementSystem) PlayerDamaged() {
+	as.run[StatTimeWithoutDamage] = 0
+}
+
+// Count the time without damage while a level is being fought and age the toasts
+func (as *AchievementSystem) Update(dt float64, fighting bool) {
+	if fighting {
+		as.run[StatTimeWithoutDamage] += dt
+		as.check(StatTimeWithoutDamage)
+	}
+
+	alive := as.toasts[:0]
+	for i, toast := range as.toasts {
+		// Only the oldest toast is on screen, the others wait their turn
+		if i == 0 {
+			toast.life -= float32(dt)
+		}
+		if toast.life > 0 {
+			alive = append(alive, toast)
+		}
+	}
+	as.toasts = alive
+}
+
+// Unlock every achievement on this stat that reached its target
+func (as *AchievementSystem) check(stat string) {
+	for i := range as.defs {
+		def := &as.defs[i]
+		if def.Stat != stat || as.IsUnlocked(def.ID) {
+			continue
+		}
+
+		if def.OnlyWeapon != "" && as.usedOtherWeapon(def.OnlyWeapon) {
+			continue
+		}
+
+		value := as.run[def.key()]
+		if def.Scope == ScopeLifetime {
+			value = as.state.Lifetime[def.key()]
+		}
+		if value >= def.Target {
+			as.unlock(def)
+		}
+	}
+}
+
+func (as *AchievementSystem) usedOtherWeapon(weapon string) bool {
+	for used := range as.weaponsUsed {
+		if used != weapon {
+			return true
+		}
+	}
+	return false
+}
+
+func (as *AchievementSystem) unlock(def *AchievementDef) {
+	as.state.Unlocked[def.ID] = time.Now().Format(time.RFC3339)
+	as.toasts = append(as.toasts, AchievementToast{def: def, life: toastDuration})
+	PlaySFX(SoundPickup)
+
+	// Unlocks are rare, save right away so they're never lost
+	as.Save()
+}
+
+// Whether the achievement was unlocked in this or an earlier run
+func (as *AchievementSystem) IsUnlocked(id string) bool {
+	_, ok := as.state.Unlocked[id]
+	return ok
+}
+
+// Number of unlocked achievements and the total
+func (as *AchievementSystem) Progress() (int, int) {
+	unlocked := 0
+	for i := range as.defs {
+		if as.IsUnlocked(as.defs[i].ID) {
+			unlocked++
+		}
+	}
+	return unlocked, len(as.defs)
+}
+
+// Draw the current toast sliding in from the bottom left
+func (as *AchievementSystem) Render() {
+	if len(as.toasts) == 0 {
+		return
+	}
+	toast := as.toasts[0]
+
+	// Slide in, hold, slide out
+	elapsed := toastDuration - toast.life
+	slide := float32(1)
+	if elapsed < toastSlideTime {
+		slide = elapsed / toastSlideTime
+	} else if toast.life < toastSlideTime {
+		slide = toast.life / toastSlideTime
+	}
+
+	x := -toastWidth + int32(float32(toastWidth+toastMarginLeft)*slide)
+	y := int32(rl.GetScreenHeight()) - toastHeight - 40
+
+	rl.DrawRectangle(x, y, toastWidth, toastHeight, rl.ColorAlpha(rl.Black, 0.8))
+	rl.DrawRectangleLines(x, y, toastWidth, toastHeight, rl.Gold)
+	rl.DrawText("ACHIEVEMENT UNLOCKED", x+12, y+8, 15, rl.Gold)
+	rl.DrawText(toast.def.Name, x+12, y+26, 22, rl.White)
+	rl.DrawText(toast.def.Description, x+12, y+50, 14, rl.LightGray)
+}
+
+// The achievement system lives for the whole game
+func (as *AchievementSystem) Destroyed() bool {
+	return false
+}
+
+func (as *AchievementSystem) Layer() RenderLayer {
+	return LayerHUD
+}
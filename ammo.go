@@ -0,0 +1,107 @@
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// AmmoType is the kind of rounds a weapon loads
+type AmmoType int
+
+const (
+	AmmoPistol AmmoType = iota
+	AmmoRifle
+	AmmoShells
+	AmmoBelt
+	ammoTypeCount
+)
+
+// AmmoDef describes one type of ammunition
+type AmmoDef struct {
+	name             string
+	carryCap         int // Most rounds the player can carry outside the magazines
+	start            int // Rounds the player starts with
+	lootMin, lootMax int // Rounds in an ammo box
+	color            rl.Color
+}
+
+var ammoDefs = [ammoTypeCount]AmmoDef{
+	AmmoPistol: {name: "Pistol", carryCap: 120, start: 48, lootMin: 24, lootMax: 48, color: rl.Green},
+	AmmoRifle:  {name: "Rifle", carryCap: 240, start: 50, lootMin: 30, lootMax: 90, color: rl.SkyBlue},
+	AmmoShells: {name: "Shells", carryCap: 48, start: 8, lootMin: 8, lootMax: 24, color: rl.Orange},
+	AmmoBelt:   {name: "Belt", carryCap: 600, start: 0, lootMin: 100, lootMax: 300, color: rl.Gold},
+}
+
+var (
+	carriedAmmoWeight int32 = 5 // Drop weight of ammo for a carried gun
+	otherAmmoWeight   int32 = 1 // Drop weight of ammo for a gun that isn't carried
+)
+
+// Weapons that can show up in the arena
+var lootWeapons = []weapon{MITRA, SHOTGUN, MINIGUN}
+
+// Starting rounds of every type
+func startingAmmo() [ammoTypeCount]int {
+	var ammo [ammoTypeCount]int
+	for t, def := range ammoDefs {
+		ammo[t] = def.start
+	}
+	return ammo
+}
+
+// Spare rounds for the weapon in hand
+func (p *player) Ammo() int {
+	return p.ammo[p.currentWeapon.ammoType]
+}
+
+// Add rounds up to the carry cap, returns how many fit
+func (p *player) AddAmmo(t AmmoType, amount int) int {
+	space := ammoDefs[t].carryCap - p.ammo[t]
+	if amount > space {
+		amount = space
+	}
+	if amount < 0 {
+		amount = 0
+	}
+	p.ammo[t] += amount
+	return amount
+}
+
+// Take up to amount rounds, returns how many were taken
+func (p *player) TakeAmmo(t AmmoType, amount int) int {
+	if amount > p.ammo[t] {
+		amount = p.ammo[t]
+	}
+	p.ammo[t] -= amount
+	return amount
+}
+
+// Pick the type of an ammo drop, mostly for the guns the player carries
+func RandomAmmoType(p *player) AmmoType {
+	var weights [ammoTypeCount]int32
+
+	// Only types some gun actually consumes can drop
+	for _, w := range lootWeapons {
+		if w.usesAmmo && weights[w.ammoType] < otherAmmoWeight {
+			weights[w.ammoType] = otherAmmoWeight
+		}
+	}
+	for _, slot := range p.inventory.slots {
+		if slot.occupied && slot.weapon.usesAmmo {
+			weights[slot.weapon.ammoType] = carriedAmmoWeight
+		}
+	}
+
+	total := int32(0)
+	for _, weight := range weights {
+		total += weight
+	}
+
+	roll := RandomValue(0, total-1)
+	for t, weight := range weights {
+		if roll < weight {
+			return AmmoType(t)
+		}
+		roll -= weight
+	}
+	return AmmoRifle
+}
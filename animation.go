@@ -0,0 +1,231 @@
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Names of the clips every sprite sheet is expected to define
+const (
+	ClipIdle   = "idle"
+	ClipWalk   = "walk"
+	ClipShoot  = "shoot"
+	ClipReload = "reload"
+	ClipHurt   = "hurt"
+	ClipDeath  = "death"
+)
+
+// AnimationClip describes a run of frames on one row of a sprite sheet
+type AnimationClip struct {
+	row        int     // Row of the sheet the clip lives on
+	startFrame int     // First column of the clip
+	frameCount int     // Number of frames in the clip
+	fps        float32 // Playback speed in frames per second
+	loop       bool    // Whether the clip starts over when it ends
+}
+
+// Duration of one full pass of the clip in seconds
+func (c AnimationClip) Duration() float32 {
+	if c.fps <= 0 {
+		return 0
+	}
+	return float32(c.frameCount) / c.fps
+}
+
+// SpriteSheetDef describes how to load a sprite sheet and cut it into clips
+type SpriteSheetDef struct {
+	path        string
+	frameWidth  int32
+	frameHeight int32
+	clips       map[string]AnimationClip
+}
+
+// SpriteSheet is a texture split into equally sized frames with named clips
+type SpriteSheet struct {
+	texture     rl.Texture2D
+	frameWidth  float32
+	frameHeight float32
+	clips       map[string]AnimationClip
+}
+
+var (
+	playerSheetDef = SpriteSheetDef{
+		path:        "assets/player_sheet.png",
+		frameWidth:  64,
+		frameHeight: 64,
+		clips: map[string]AnimationClip{
+			ClipIdle:   {row: 0, frameCount: 4, fps: 6, loop: true},
+			ClipWalk:   {row: 1, frameCount: 6, fps: 10, loop: true},
+			ClipShoot:  {row: 2, frameCount: 3, fps: 15, loop: false},
+			ClipReload: {row: 3, frameCount: 6, fps: 8, loop: true},
+			ClipHurt:   {row: 4, frameCount: 2, fps: 10, loop: false},
+			ClipDeath:  {row: 5, frameCount: 6, fps: 8, loop: false},
+		},
+	}
+
+	// Same clips as playerSheetDef, drawn facing the camera
+	playerFrontSheetDef = SpriteSheetDef{
+		path:        "assets/player_front_sheet.png",
+		frameWidth:  playerSheetDef.frameWidth,
+		frameHeight: playerSheetDef.frameHeight,
+		clips:       playerSheetDef.clips,
+	}
+
+	zombieSheetDef = SpriteSheetDef{
+		path:        "assets/zombie_sheet.png",
+		frameWidth:  64,
+		frameHeight: 64,
+		clips: map[string]AnimationClip{
+			ClipIdle:  {row: 0, frameCount: 4, fps: 4, loop: true},
+			ClipWalk:  {row: 1, frameCount: 6, fps: 8, loop: true},
+			ClipHurt:  {row: 2, frameCount: 2, fps: 12, loop: false},
+			ClipDeath: {row: 3, frameCount: 5, fps: 8, loop: false},
+		},
+	}
+)
+
+// Load a sprite sheet, falling back to a single static texture if the sheet is missing
+func LoadSpriteSheet(def SpriteSheetDef, fallbackPath string) *SpriteSheet {
+	texture := rl.LoadTexture(def.path)
+	if texture.ID > 0 {
+		rl.TraceLog(rl.LogInfo, "Successfully loaded sprite sheet from %s", def.path)
+		return &SpriteSheet{
+			texture:     texture,
+			frameWidth:  float32(def.frameWidth),
+			frameHeight: float32(def.frameHeight),
+			clips:       def.clips,
+		}
+	}
+
+	if fallbackPath == "" {
+		return nil
+	}
+
+	texture = rl.LoadTexture(fallbackPath)
+	if texture.ID == 0 {
+		rl.TraceLog(rl.LogWarning, "Failed to load sprite %s!", fallbackPath)
+		return nil
+	}
+	rl.TraceLog(rl.LogInfo, "Sprite sheet %s not found, using static sprite %s", def.path, fallbackPath)
+
+	// Every clip shows the whole texture but keeps its duration,
+	// so one-shot clips like death still take the same time to finish
+	clips := make(map[string]AnimationClip, len(def.clips))
+	for name, clip := range def.clips {
+		clips[name] = AnimationClip{
+			frameCount: 1,
+			fps:        1 / clip.Duration(),
+			loop:       clip.loop,
+		}
+	}
+
+	return &SpriteSheet{
+		texture:     texture,
+		frameWidth:  float32(texture.Width),
+		frameHeight: float32(texture.Height),
+		clips:       clips,
+	}
+}
+
+// Unload the sheet texture
+func (s *SpriteSheet) Unload() {
+	if s != nil && s.texture.ID > 0 {
+		rl.UnloadTexture(s.texture)
+	}
+}
+
+// Draw the animator's current frame centered on pos, scaled to the given height
+func (s *SpriteSheet) Draw(a *Animator, pos rl.Vector2, height float32, flip bool, tint rl.Color) {
+	scale := height / s.frameHeight
+	width := s.frameWidth * scale
+
+	source := rl.NewRectangle(
+		float32(a.clip.startFrame+a.Frame())*s.frameWidth,
+		float32(a.clip.row)*s.frameHeight,
+		s.frameWidth,
+		s.frameHeight,
+	)
+
+	// A negative source width mirrors the frame horizontally
+	if flip {
+		source.Width = -source.Width
+	}
+
+	rl.DrawTexturePro(
+		s.texture,
+		source,
+		rl.NewRectangle(pos.X-width/2, pos.Y-height/2, width, height),
+		rl.NewVector2(0, 0),
+		0,
+		tint,
+	)
+}
+
+// Animator plays clips of a sprite sheet
+type Animator struct {
+	clipName string
+	clip     AnimationClip
+	time     float32 // Time spent in the current clip
+}
+
+// Switch to a clip, keeping its progress if it's already playing
+func (a *Animator) Play(sheet *SpriteSheet, name string) {
+	if a.clipName == name {
+		return
+	}
+	a.Restart(sheet, name)
+}
+
+// Start a clip from its first frame
+func (a *Animator) Restart(sheet *SpriteSheet, name string) {
+	a.clipName = name
+	a.time = 0
+	if sheet != nil {
+		a.clip = sheet.clips[name]
+	}
+}
+
+// Advance the current clip
+func (a *Animator) Update(dt float64) {
+	a.time += float32(dt)
+
+	// Wrap looping clips to avoid the timer growing forever
+	duration := a.clip.Duration()
+	if a.clip.loop && duration > 0 && a.time >= duration {
+		a.time -= duration * float32(int(a.time/duration))
+	}
+}
+
+// Index of the current frame inside the clip
+func (a *Animator) Frame() int {
+	if a.clip.frameCount <= 1 {
+		return 0
+	}
+
+	frame := int(a.time * a.clip.fps)
+	if a.clip.loop {
+		return frame % a.clip.frameCount
+	}
+	if frame >= a.clip.frameCount {
+		return a.clip.frameCount - 1
+	}
+	return frame
+}
+
+// Whether a one-shot clip has reached its end
+func (a *Animator) Finished() bool {
+	return !a.clip.loop && a.time >= a.clip.Duration()
+}
+
+// How far through the clip we are, from 0 to 1
+func (a *Animator) Progress() float32 {
+	duration := a.clip.Duration()
+	if duration <= 0 {
+		return 1
+	}
+	return rl.Clamp(a.time/duration, 0, 1)
+}
+
+// Name of the clip currently playing
+func (a *Animator) Clip() string {
+	return a.clipName
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// SoundID identifies one of the game's sound effects
+type SoundID int
+
+const (
+	SoundPistolShot SoundID = iota
+	SoundMitraShot
+	SoundShotgunShot
+	SoundMinigunShot
+	SoundReloadStart
+	SoundReloadFinish
+	SoundEmptyClick
+	SoundGrenadeBeep
+	SoundExplosion
+	SoundZombieGroan
+	SoundZombieHit
+	SoundPickup
+	SoundPlayerHurt
+	soundCount
+)
+
+// SoundCategory groups sounds that share a volume setting
+type SoundCategory int
+
+const (
+	CategoryWeapons SoundCategory = iota
+	CategoryExplosions
+	CategoryEnemies
+	CategoryPickups
+	CategoryPlayer
+	categoryCount
+)
+
+// Names the categories are saved under and labels shown in the settings
+var (
+	categoryNames  = [categoryCount]string{"weapons", "explosions", "enemies", "pickups", "player"}
+	categoryLabels = [categoryCount]string{"Weapons", "Explosions", "Zombies", "Pickups", "Player"}
+)
+
+// soundDef describes how a sound is loaded and played
+type soundDef struct {
+	path        string
+	category    SoundCategory
+	volume      float32 // Base volume of the sound (0-1)
+	voices      int     // How many copies of the sound can play at the same time
+	minInterval float64 // Minimum time in seconds between two plays of the sound
+	pitchJitter float32 // Random pitch variation applied on each play
+}
+
+var soundDefs = [soundCount]soundDef{
+	SoundPistolShot:   {path: "assets/sfx/pistol_shot.wav", category: CategoryWeapons, volume: 0.8, voices: 3, minInterval: 0.05, pitchJitter: 0.05},
+	SoundMitraShot:    {path: "assets/sfx/mitra_shot.wav", category: CategoryWeapons, volume: 0.7, voices: 4, minInterval: 0.05, pitchJitter: 0.05},
+	SoundShotgunShot:  {path: "assets/sfx/shotgun_shot.wav", category: CategoryWeapons, volume: 0.9, voices: 2, minInterval: 0.1, pitchJitter: 0.04},
+	SoundMinigunShot:  {path: "assets/sfx/minigun_shot.wav", category: CategoryWeapons, volume: 0.5, voices: 4, minInterval: 0.04, pitchJitter: 0.08}, // Few voices so the minigun doesn't clip
+	SoundReloadStart:  {path: "assets/sfx/reload_start.wav", category: CategoryWeapons, volume: 0.7, voices: 1},
+	SoundReloadFinish: {path: "assets/sfx/reload_finish.wav", category: CategoryWeapons, volume: 0.7, voices: 1},
+	SoundEmptyClick:   {path: "assets/sfx/empty_click.wav", category: CategoryWeapons, volume: 0.6, voices: 1, minInterval: 0.25},
+	SoundGrenadeBeep:  {path: "assets/sfx/grenade_beep.wav", category: CategoryExplosions, volume: 0.5, voices: 4, minInterval: 0.05},
+	SoundExplosion:    {path: "assets/sfx/explosion.wav", category: CategoryExplosions, volume: 1.0, voices: 3, minInterval: 0.05, pitchJitter: 0.1},
+	SoundZombieGroan:  {path: "assets/sfx/zombie_groan.wav", category: CategoryEnemies, volume: 0.5, voices: 3, minInterval: 0.4, pitchJitter: 0.15},
+	SoundZombieHit:    {path: "assets/sfx/zombie_hit.wav", category: CategoryEnemies, volume: 0.6, voices: 4, minInterval: 0.03, pitchJitter: 0.1},
+	SoundPickup:       {path: "assets/sfx/pickup.wav", category: CategoryPickups, volume: 0.8, voices: 2},
+	SoundPlayerHurt:   {path: "assets/sfx/player_hurt.wav", category: CategoryPlayer, volume: 0.8, voices: 1, minInterval: 0.3, pitchJitter: 0.05},
+}
+
+var (
+	masterVolume   float32 = 0.8
+	categoryVolume         = [categoryCount]float32{
+		CategoryWeapons:    0.8,
+		CategoryExplosions: 1.0,
+		CategoryEnemies:    0.7,
+		CategoryPickups:    0.8,
+		CategoryPlayer:     1.0,
+	}
+)
+
+// soundVoices holds the loaded copies of one sound effect
+type soundVoices struct {
+	voices     []rl.Sound
+	next       int     // Next voice to use (oldest one gets reused)
+	lastPlayed float64 // When the sound was last played
+}
+
+var (
+	audioReady bool // False when there is no audio device, every play becomes a no-op
+	sounds     [soundCount]soundVoices
+)
+
+// Initialize the audio device and load all sound effects
+func InitAudio() {
+	rl.InitAudioDevice()
+
+	if !rl.IsAudioDeviceReady() {
+		rl.TraceLog(rl.LogWarning, "No audio device available! Game will run without sound.")
+		audioReady = false
+		return
+	}
+	audioReady = true
+	rl.SetMasterVolume(masterVolume)
+
+	rl.TraceLog(rl.LogWarning, "Loading sound effects...")
+
+	for id, def := range soundDefs {
+		wave := rl.LoadWave(def.path)
+		if wave.FrameCount == 0 {
+			rl.TraceLog(rl.LogWarning, "Failed to load sound %s! It will be silent.", def.path)
+			continue
+		}
+
+		// Every voice is a separate copy so overlapping plays don't cut each other off
+		voices := make([]rl.Sound, 0, def.voices)
+		for i := 0; i < def.voices; i++ {
+			voices = append(voices, rl.LoadSoundFromWave(wave))
+		}
+		rl.UnloadWave(wave)
+
+		sounds[id].voices = voices
+	}
+}
+
+// Unload all sound effects and close the audio device
+func UnloadAudio() {
+	if !audioReady {
+		return
+	}
+
+	for id := range sounds {
+		for _, voice := range sounds[id].voices {
+			rl.UnloadSound(voice)
+		}
+		sounds[id].voices = nil
+	}
+
+	rl.CloseAudioDevice()
+	audioReady = false
+}
+
+// Set the volume of everything the game plays (0-1)
+func SetMasterVolume(volume float32) {
+	masterVolume = rl.Clamp(volume, 0, 1)
+	if audioReady {
+		rl.SetMasterVolume(masterVolume)
+	}
+}
+
+// Set the volume of a sound category (0-1)
+func SetCategoryVolume(category SoundCategory, volume float32) {
+	categoryVolume[category] = rl.Clamp(volume, 0, 1)
+}
+
+// Play a sound effect centered in the stereo field
+func PlaySFX(id SoundID) {
+	playSFX(id, 0.5)
+}
+
+// Play a sound effect panned according to its position on screen
+func PlaySFXAt(id SoundID, pos rl.Vector2) {
+	screenWidth := float32(rl.GetScreenWidth())
+	if screenWidth <= 0 {
+		playSFX(id, 0.5)
+		return
+	}
+
+	// Raylib pans 1.0 fully left and 0.0 fully right
+	pan := 1 - rl.Clamp(pos.X/screenWidth, 0, 1)
+
+	// Keep some sound in both ears so things at the edges don't feel detached
+	pan = 0.5 + (pan-0.5)*0.8
+
+	playSFX(id, pan)
+}
+
+func playSFX(id SoundID, pan float32) {
+	if !audioReady {
+		return
+	}
+
+	slot := &sounds[id]
+	if len(slot.voices) == 0 {
+		return
+	}
+
+	def := soundDefs[id]
+	currentTime := rl.GetTime()
+
+	// Limit how often the same sound can be triggered
+	if currentTime-slot.lastPlayed < def.minInterval {
+		return
+	}
+	slot.lastPlayed = currentTime
+
+	// Reuse the oldest voice, this caps the number of overlapping copies
+	voice := slot.voices[slot.next]
+	slot.next = (slot.next + 1) % len(slot.voices)
+
+	pitch := float32(1.0)
+	if def.pitchJitter > 0 {
+		pitch += def.pitchJitter * float32(rl.GetRandomValue(-100, 100)) / 100.0
+	}
+
+	rl.SetSoundVolume(voice, def.volume*categoryVolume[def.category])
+	rl.SetSoundPitch(voice, pitch)
+	rl.SetSoundPan(voice, pan)
+	rl.PlaySound(voice)
+}
+
+// Play the sounds of gameplay events
+func subscribeAudio(bus *EventBus) {
+	Subscribe(bus, func(e ShotFired) {
+		PlaySFXAt(e.Sound, e.Origin)
+	})
+	Subscribe(bus, func(e GrenadeExploded) {
+		PlaySFXAt(SoundExplosion, e.Pos)
+	})
+	Subscribe(bus, func(e EnemyHit) {
+		PlaySFXAt(SoundZombieHit, e.Enemy.pos)
+	})
+	Subscribe(bus, func(e DamageTaken) {
+		PlaySFXAt(SoundPlayerHurt, e.Pos)
+	})
+	Subscribe(bus, func(e PickupCollected) {
+		PlaySFXAt(SoundPickup, e.Pos)
+	})
+}
@@ -1,6 +1,10 @@
 package main
 
-import rl "github.com/gen2brain/raylib-go/raylib"
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
 
 type Collides interface {
 	Position() rl.Vector2
@@ -126,6 +130,102 @@ func (cs *CollisionSpace) RearrangeBodies(maxIters int, collidables []Collides,
 	}
 }
 
+var collisionPasses int = 4 // Times overlaps are resolved per move, more passes settle corners between blocks
+
+// Push a circle out of a rectangle along the shortest way, returns false if they don't overlap
+func pushCircleOutOfRect(center rl.Vector2, radius float32, rect rl.Rectangle) (rl.Vector2, bool) {
+	closest := rl.NewVector2(
+		rl.Clamp(center.X, rect.X, rect.X+rect.Width),
+		rl.Clamp(center.Y, rect.Y, rect.Y+rect.Height),
+	)
+	offset := rl.Vector2Subtract(center, closest)
+	distSq := offset.X*offset.X + offset.Y*offset.Y
+
+	if distSq >= radius*radius {
+		return center, false
+	}
+
+	// Outside the rectangle, push away from the closest point. On a corner this
+	// pushes diagonally, so circles round corners instead of snagging on them
+	if distSq > 0 {
+		dist := float32(math.Sqrt(float64(distSq)))
+		return rl.Vector2Add(closest, rl.Vector2Scale(offset, radius/dist)), true
+	}
+
+	// The center is inside, leave through the nearest side
+	left := center.X - rect.X
+	right := rect.X + rect.Width - center.X
+	top := center.Y - rect.Y
+	bottom := rect.Y + rect.Height - center.Y
+
+	switch {
+	case left <= right && left <= top && left <= bottom:
+		center.X = rect.X - radius
+	case right <= top && right <= bottom:
+		center.X = rect.X + rect.Width + radius
+	case top <= bottom:
+		center.Y = rect.Y - radius
+	default:
+		center.Y = rect.Y + rect.Height + radius
+	}
+	return center, true
+}
+
+// Move a circle out of every block it overlaps, returns whether it had to be moved
+func resolveCircleBlocks(center rl.Vector2, radius float32, blocks []*Block) (rl.Vector2, bool) {
+	collided := false
+	for pass := 0; pass < collisionPasses; pass++ {
+		pushed := false
+		for _, block := range blocks {
+			var hit bool
+			center, hit = pushCircleOutOfRect(center, radius, block.GetRectangle())
+			pushed = pushed || hit
+		}
+		if !pushed {
+			break
+		}
+		collided = true
+	}
+	return center, collided
+}
+
+// Move a circle by delta one axis at a time, so the part of the move along a wall is
+// kept and the actor slides instead of stopping. Returns the new position and whether
+// a block was hit
+func moveCircle(center, delta rl.Vector2, radius float32, blocks []*Block) (rl.Vector2, bool) {
+	var hitX, hitY bool
+	center.X += delta.X
+	center, hitX = resolveCircleBlocks(center, radius, blocks)
+	center.Y += delta.Y
+	center, hitY = resolveCircleBlocks(center, radius, blocks)
+	return center, hitX || hitY
+}
+
+// Check that the segment between two points doesn't cross any block
+func lineOfSightClear(from, to rl.Vector2, blocks []*Block) bool {
+	for _, block := range blocks {
+		rect := block.GetRectangle()
+
+		if rl.CheckCollisionPointRec(from, rect) || rl.CheckCollisionPointRec(to, rect) {
+			return false
+		}
+
+		corners := [4]rl.Vector2{
+			rl.NewVector2(rect.X, rect.Y),
+			rl.NewVector2(rect.X+rect.Width, rect.Y),
+			rl.NewVector2(rect.X+rect.Width, rect.Y+rect.Height),
+			rl.NewVector2(rect.X, rect.Y+rect.Height),
+		}
+		for i := range corners {
+			var hit rl.Vector2
+			if rl.CheckCollisionLines(from, to, corners[i], corners[(i+1)%4], &hit) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func (cs *CollisionSpace) Draw() {
 	for y := 0; y <= cs.Rows; y++ {
 		rl.DrawLine(0, int32(y*cs.CellHeight), int32(cs.Cols*cs.CellWidth), int32(y*cs.CellHeight), rl.Green)
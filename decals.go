@@ -0,0 +1,193 @@
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// OpenGL blend factors and equations for rl.SetBlendFactors, raylib-go doesn't export them
+const (
+	GL_ZERO                = 0
+	GL_ONE                 = 1
+	GL_SRC_ALPHA           = 0x0302
+	GL_ONE_MINUS_SRC_ALPHA = 0x0303
+	GL_FUNC_ADD            = 0x8006
+)
+
+// DecalKind is the type of mark stamped into the decal layer
+type DecalKind int
+
+const (
+	DecalBlood DecalKind = iota
+	DecalScorch
+	DecalBulletHole
+)
+
+// decalStamp is a decal waiting to be drawn into the layer
+type decalStamp struct {
+	kind     DecalKind
+	pos      rl.Vector2
+	size     float32
+	rotation float32
+}
+
+var (
+	decalLevelFadeSpeed float32 = 0.5 // Opacity lost per second when the layer fades out at level end
+	decalFadePerSecond  float32 = 0   // Slow fade of old decals while playing, 0 keeps them forever
+)
+
+// DecalLayer bakes decals into a persistent render texture,
+// so drawing them costs the same no matter how many there are
+type DecalLayer struct {
+	target    rl.RenderTexture2D
+	pending   []decalStamp // Stamps are batched and drawn once per frame
+	opacity   float32      // Opacity of the whole layer
+	fading    bool         // Fading out because the level ended
+	fadeAccum float32      // Slow fade not applied yet, small steps get lost in 8 bit alpha
+}
+
+// Shared decal layer for blood, scorch marks and bullet holes
+var decals *DecalLayer
+
+// NewDecalLayer creates an empty decal layer covering the screen
+func NewDecalLayer(width, height int32) *DecalLayer {
+	d := &DecalLayer{
+		target:  rl.LoadRenderTexture(width, height),
+		opacity: 1,
+	}
+	d.Clear()
+	return d
+}
+
+// Stamp a blood splat with a random rotation and size
+func (d *DecalLayer) StampBlood(pos rl.Vector2) {
+	// Random scale between 0.8 and 1.2 for size variation
+	scale := 0.8 + float32(rl.GetRandomValue(0, 40))/100.0
+	d.pending = append(d.pending, decalStamp{
+		kind:     DecalBlood,
+		pos:      pos,
+		size:     50 * scale,
+		rotation: float32(rl.GetRandomValue(0, 359)),
+	})
+}
+
+// Stamp a scorch mark left by an explosion
+func (d *DecalLayer) StampScorch(pos rl.Vector2, radius float32) {
+	d.pending = append(d.pending, decalStamp{kind: DecalScorch, pos: pos, size: radius})
+}
+
+// Stamp a bullet hole where a projectile hit a wall
+func (d *DecalLayer) StampBulletHole(pos rl.Vector2) {
+	d.pending = append(d.pending, decalStamp{kind: DecalBulletHole, pos: pos, size: 2 + float32(rl.GetRandomValue(0, 10))/10.0})
+}
+
+// Fade the whole layer out and clear it, used when a level ends
+func (d *DecalLayer) FadeOut() {
+	d.fading = true
+}
+
+// Remove every decal
+func (d *DecalLayer) Clear() {
+	d.pending = d.pending[:0]
+	d.clearTexture()
+}
+
+// Wipe the baked decals and reset the fading state
+func (d *DecalLayer) clearTexture() {
+	rl.BeginTextureMode(d.target)
+	rl.ClearBackground(rl.Blank)
+	rl.EndTextureMode()
+
+	d.opacity = 1
+	d.fading = false
+	d.fadeAccum = 0
+}
+
+// Bake the pending stamps and apply fading
+func (d *DecalLayer) Update(dt float64) {
+	if d.fading {
+		d.opacity -= decalLevelFadeSpeed * float32(dt)
+		if d.opacity > 0 {
+			// New decals wait until the old ones are gone so they don't fade with them
+			return
+		}
+
+		// Fully faded, start the next level with a clean floor
+		d.clearTexture()
+	}
+
+	if decalFadePerSecond > 0 {
+		d.fadeAccum += decalFadePerSecond * float32(dt)
+	}
+
+	// Nothing to draw into the texture this frame
+	if len(d.pending) == 0 && d.fadeAccum < 0.02 {
+		return
+	}
+
+	rl.BeginTextureMode(d.target)
+
+	// Erase a bit of everything already baked by scaling the alpha down
+	if d.fadeAccum >= 0.02 {
+		rl.BeginBlendMode(rl.BlendCustom)
+		rl.SetBlendFactors(GL_ZERO, GL_ONE_MINUS_SRC_ALPHA, GL_FUNC_ADD)
+		rl.DrawRectangle(0, 0, d.target.Texture.Width, d.target.Texture.Height, rl.ColorAlpha(rl.White, d.fadeAccum))
+		rl.EndBlendMode()
+		d.fadeAccum = 0
+	}
+
+	for _, stamp := range d.pending {
+		d.draw(stamp)
+	}
+	d.pending = d.pending[:0]
+
+	rl.EndTextureMode()
+}
+
+func (d *DecalLayer) draw(stamp decalStamp) {
+	switch stamp.kind {
+	case DecalBlood:
+		if bloodTexture.ID > 0 {
+			// Draw the blood sprite with rotation
+			rl.DrawTexturePro(
+				bloodTexture,
+				rl.NewRectangle(0, 0, float32(bloodTexture.Width), float32(bloodTexture.Height)),
+				rl.NewRectangle(stamp.pos.X, stamp.pos.Y, stamp.size, stamp.size),
+				rl.NewVector2(stamp.size/2, stamp.size/2),
+				stamp.rotation,
+				rl.White,
+			)
+		} else {
+			// Fallback if texture not loaded
+			rl.DrawCircleV(stamp.pos, 10, rl.Maroon)
+		}
+	case DecalScorch:
+		// Dark soot that gets lighter towards the edge
+		rl.DrawCircleGradient(int32(stamp.pos.X), int32(stamp.pos.Y), stamp.size, rl.NewColor(20, 15, 10, 200), rl.NewColor(20, 15, 10, 0))
+		rl.DrawCircleV(stamp.pos, stamp.size*0.3, rl.NewColor(10, 8, 5, 160))
+	case DecalBulletHole:
+		rl.DrawCircleV(stamp.pos, stamp.size, rl.NewColor(15, 15, 15, 230))
+	}
+}
+
+// Draw the baked layer
+func (d *DecalLayer) Render() {
+	texture := d.target.Texture
+
+	// Render textures are stored upside down, flip them with a negative height
+	rl.DrawTextureRec(
+		texture,
+		rl.NewRectangle(0, 0, float32(texture.Width), -float32(texture.Height)),
+		rl.NewVector2(0, 0),
+		rl.ColorAlpha(rl.White, d.opacity),
+	)
+}
+
+// The decal layer lives for the whole game
+func (d *DecalLayer) Destroyed() bool {
+	return false
+}
+
+// Free the render texture
+func (d *DecalLayer) Unload() {
+	rl.UnloadRenderTexture(d.target)
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const DROPS_FILE = "assets/drops.json"
+
+// Kinds of things an enemy can drop
+const (
+	DropWeapon  = "weapon"
+	DropAmmo    = "ammo"
+	DropGrenade = "grenade"
+	DropMedkit  = "medkit"
+)
+
+// Keep the old pickups that appear anywhere on the screen on timers
+var timedLootSpawns bool = false
+
+// DropEntry is one thing a drop table can roll
+type DropEntry struct {
+	Kind           string  `json:"kind"`
+	Target         string  `json:"target,omitempty"` // Weapon, ammo type or grenade kind, empty for a random one
+	Amount         int     `json:"amount,omitempty"` // 0 for the usual amount of a pickup
+	Weight         float64 `json:"weight"`           // Weight on the first level
+	WeightPerLevel float64 `json:"weightPerLevel"`   // Added to the weight for every level after the first
+	MinLevel       int     `json:"minLevel"`         // First level the entry can drop on
+
+	// Resolved from Target when loading
+	weapon      weapon
+	ammoType    AmmoType
+	grenadeKind GrenadeKind
+}
+
+// DropTable is what one type of enemy drops when killed
+type DropTable struct {
+	Enemy          string      `json:"enemy"`
+	Chance         float64     `json:"chance"`         // Chance of dropping anything on the first level
+	ChancePerLevel float64     `json:"chancePerLevel"` // Added to the chance for every level after the first
+	Entries        []DropEntry `json:"entries"`
+}
+
+// PityDef guarantees an ammo drop after a number of kills while the player is low on ammo
+type PityDef struct {
+	LowAmmo float64 `json:"lowAmmo"` // Share of the carry cap under which ammo counts as low
+	Kills   int     `json:"kills"`   // Kills without ammo dropping before it's guaranteed
+}
+
+type dropsFile struct {
+	Pity   PityDef     `json:"pity"`
+	Tables []DropTable `json:"tables"`
+}
+
+var (
+	dropTables []DropTable
+	dropPity   PityDef
+)
+
+// Load the drop tables, entries with an unknown target are left out
+func InitDropTables() {
+	data, err := os.ReadFile(DROPS_FILE)
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "Failed to load drop tables from %s! Enemies drop no loot.", DROPS_FILE)
+		return
+	}
+	var file dropsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		rl.TraceLog(rl.LogWarning, "Failed to parse %s: %s", DROPS_FILE, err.Error())
+		return
+	}
+
+	dropPity = file.Pity
+	for _, table := range file.Tables {
+		entries := table.Entries[:0]
+		for _, entry := range table.Entries {
+			if entry.resolve() {
+				entries = append(entries, entry)
+			} else {
+				rl.TraceLog(rl.LogWarning, "Unknown %s %s in the drop table of %s", entry.Kind, entry.Target, table.Enemy)
+			}
+		}
+		table.Entries = entries
+		dropTables = append(dropTables, table)
+	}
+}
+
+// Find what Target names, returns false if it doesn't exist
+func (entry *DropEntry) resolve() bool {
+	if entry.Target == "" {
+		return entry.Kind == DropWeapon || entry.Kind == DropAmmo || entry.Kind == DropGrenade || entry.Kind == DropMedkit
+	}
+
+	switch entry.Kind {
+	case DropWeapon:
+		for _, w := range lootWeapons {
+			if w.weaponName == entry.Target {
+				entry.weapon = w
+				return true
+			}
+		}
+	case DropAmmo:
+		for t, ammo := range ammoDefs {
+			if ammo.name == entry.Target {
+				entry.ammoType = AmmoType(t)
+				return true
+			}
+		}
+	case DropGrenade:
+		for kind, grenade := range grenadeDefs {
+			if grenade.name == entry.Target {
+				entry.grenadeKind = GrenadeKind(kind)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Weight of the entry on a level, 0 before its first level
+func (entry *DropEntry) weightAt(level int) float64 {
+	if level < entry.MinLevel {
+		return 0
+	}
+	weight := entry.Weight + entry.WeightPerLevel*float64(level-1)
+	if weight < 0 {
+		return 0
+	}
+	return weight
+}
+
+// Drop table of a type of enemy, nil if it drops nothing
+func dropTableFor(enemy string) *DropTable {
+	for i := range dropTables {
+		if dropTables[i].Enemy == enemy {
+			return &dropTables[i]
+		}
+	}
+	return nil
+}
+
+// Roll a number in [0, 1)
+func rollChance() float64 {
+	return float64(RandomValue(0, 9999)) / 10000
+}
+
+// LootDrop is a rolled drop, ready to be put in the world
+type LootDrop struct {
+	Kind        string
+	weapon      weapon
+	ammoType    AmmoType
+	grenadeKind GrenadeKind
+	amount      int
+}
+
+// Turn an entry into a drop, picking the random target and amount if the entry leaves them open
+func (entry *DropEntry) drop(p *player) LootDrop {
+	drop := LootDrop{
+		Kind:        entry.Kind,
+		weapon:      entry.weapon,
+		ammoType:    entry.ammoType,
+		grenadeKind: entry.grenadeKind,
+		amount:      entry.Amount,
+	}
+
+	switch entry.Kind {
+	case DropWeapon:
+		if entry.Target == "" {
+			drop.weapon = lootWeapons[RandomValue(0, int32(len(lootWeapons)-1))]
+		}
+	case DropAmmo:
+		if entry.Target == "" {
+			drop.ammoType = RandomAmmoType(p)
+		}
+		if drop.amount == 0 {
+			drop.amount = ammoBoxAmount(drop.ammoType)
+		}
+	case DropGrenade:
+		if entry.Target == "" {
+			drop.grenadeKind = RandomGrenadeKind()
+		}
+		if drop.amount == 0 {
+			drop.amount = grenadeDefs[drop.grenadeKind].lootAmount
+		}
+	case DropMedkit:
+		if drop.amount == 0 {
+			drop.amount = medkitHeal
+		}
+	}
+	return drop
+}
+
+// Rounds in a box of ammo
+func ammoBoxAmount(t AmmoType) int {
+	return int(RandomValue(int32(ammoDefs[t].lootMin), int32(ammoDefs[t].lootMax)))
+}
+
+// The carried gun lowest on ammo, returns false if none of them is under the pity threshold
+func lowestAmmo(p *player) (AmmoType, bool) {
+	lowest := AmmoRifle
+	lowestShare := 1.0
+	for _, slot := range p.inventory.slots {
+		if !slot.occupied || !slot.weapon.usesAmmo {
+			continue
+		}
+		t := slot.weapon.ammoType
+		share := float64(p.ammo[t]) / float64(ammoDefs[t].carryCap)
+		if share < lowestShare {
+			lowest, lowestShare = t, share
+		}
+	}
+	return lowest, lowestShare < dropPity.LowAmmo
+}
+
+// LootDropper rolls the drop tables when enemies die and keeps the pity counter of a run
+type LootDropper struct {
+	dryKills int // Kills while low on ammo since ammo last dropped
+}
+
+// Roll the drop of a killed enemy, returns false when it drops nothing. A player low on
+// ammo is guaranteed a box for their gun after enough kills without one
+func (d *LootDropper) Roll(e *Enemy, level int, p *player) (LootDrop, bool) {
+	lowType, low := lowestAmmo(p)
+	if !low {
+		d.dryKills = 0
+	}
+
+	drop, ok := d.rollTable(e, level, p)
+	if ok && drop.Kind == DropAmmo {
+		d.dryKills = 0
+		return drop, true
+	}
+
+	if low {
+		d.dryKills++
+		if dropPity.Kills > 0 && d.dryKills >= dropPity.Kills {
+			d.dryKills = 0
+			return LootDrop{Kind: DropAmmo, ammoType: lowType, amount: ammoBoxAmount(lowType)}, true
+		}
+	}
+	return drop, ok
+}
+
+// Roll the drop chance, then pick an entry by its weight on the level
+func (d *LootDropper) rollTable(e *Enemy, level int, p *player) (LootDrop, bool) {
+	table := dropTableFor(e.kind)
+	if table == nil {
+		return LootDrop{}, false
+	}
+
+	chance := table.Chance + table.ChancePerLevel*float64(level-1)
+	if rollChance() >= chance {
+		return LootDrop{}, false
+	}
+
+	total := 0.0
+	for i := range table.Entries {
+		total += table.Entries[i].weightAt(level)
+	}
+	if total <= 0 {
+		return LootDrop{}, false
+	}
+
+	roll := rollChance() * total
+	for i := range table.Entries {
+		entry := &table.Entries[i]
+		weight := entry.weightAt(level)
+		if roll < weight {
+			return entry.drop(p), true
+		}
+		roll -= weight
+	}
+	return LootDrop{}, false
+}
+
+// Start a new run without any pity built up
+func (d *LootDropper) Reset() {
+	d.dryKills = 0
+}
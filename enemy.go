@@ -4,8 +4,18 @@ import (
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+// Types of enemies, the type picks the drop table
+const EnemyZombie = "zombie"
+
 var enemySpeed float32 = 70
-var enemySprite rl.Texture2D // Single shared texture for all enemies
+var knockbackDrag float32 = 6 // Fraction of the knockback speed lost per second
+
+var (
+	enemyAttackReach    float32 = 12   // How far past touching a zombie can hit
+	enemyWindupTime     float64 = 0.35 // Seconds a zombie winds up before the hit lands
+	enemyAttackCooldown float64 = 1.0  // Seconds between attacks of one zombie
+)
+var enemySheet *SpriteSheet // Single shared sprite sheet for all enemies
 
 // Variable to store blocks globally for enemy collision checks
 var globalBlocks []*Block
@@ -17,117 +27,141 @@ func UpdateGlobalBlocks(blocks []*Block) {
 
 // Initialize the enemy sprite
 func InitEnemySprite() {
-	// Try different possible paths for the zombie sprite
-	path := "assets/zombie.png"
-
 	// Print working directory for debugging
 	rl.TraceLog(rl.LogWarning, "Loading zombie sprite...")
 
-	// Try to load the sprite
-	enemySprite = rl.LoadTexture(path)
-
-	// Check if sprite loaded successfully
-	if enemySprite.ID > 0 {
-		rl.TraceLog(rl.LogInfo, "Successfully loaded zombie sprite from %s", path)
-	}
+	// Try to load the animated sheet, falling back to the static zombie sprite
+	enemySheet = LoadSpriteSheet(zombieSheetDef, "assets/zombie.png")
 
-	if enemySprite.ID == 0 {
+	if enemySheet == nil {
 		rl.TraceLog(rl.LogWarning, "Failed to load zombie sprite! Will use fallback circle.")
 	}
 }
 
 // Unload the enemy sprite
 func UnloadEnemySprite() {
-	if enemySprite.ID > 0 {
-		rl.UnloadTexture(enemySprite)
-	}
+	enemySheet.Unload()
 }
 
 type Enemy struct {
 	pos               rl.Vector2
+	kind              string // Type of the enemy
 	bodyRadius        float32
 	health, maxHealth float32
 	damage            float32
 	destroyed         bool
+	dying             bool       // Killed and playing the death clip, destroyed once it ends
+	visibility        int        // How the enemy is seen on night levels
+	groanTimer        float64    // Seconds until the zombie groans again
+	anim              Animator   // Current animation clip
+	level             int        // Level the enemy was spawned on, scales the points it's worth
+	chillTimer        float64    // Seconds left slowed down by a cryo grenade
+	knockback         rl.Vector2 // Speed the enemy was thrown back with, slows down to a stop
+	windup            float64    // Seconds until the attack being wound up lands, 0 when not attacking
+	attackCooldown    float64    // Seconds until the enemy can attack again
 }
 
 func NewEnemy(pos rl.Vector2, maxHealth, damage, bodyRadius float32) *Enemy {
 	s := Enemy{
 		pos:        pos,
+		kind:       EnemyZombie,
 		bodyRadius: bodyRadius,
 		damage:     damage,
 		health:     maxHealth,
 		maxHealth:  maxHealth, // Should be set based on level
+		groanTimer: float64(RandomValue(20, 80)) / 10.0,
 	}
+	s.anim.Play(enemySheet, ClipWalk)
 	return &s
 }
 
 func (e *Enemy) Move(playerPos rl.Vector2, dt float64) {
+	// Dead zombies stay where they fell
+	if e.dying {
+		return
+	}
+
 	dtspeed := dt * float64(enemySpeed)
 
-	// Store original position
-	originalPos := e.pos
+	// Frozen zombies drag themselves along
+	if e.chillTimer > 0 {
+		e.chillTimer -= dt
+		dtspeed *= float64(cryoSlowFactor)
+	}
+
+	// Groan every few seconds, the sound itself limits how many play at once
+	e.groanTimer -= dt
+	if e.groanTimer <= 0 {
+		e.groanTimer = float64(RandomValue(40, 120)) / 10.0
+		PlaySFXAt(SoundZombieGroan, e.pos)
+	}
+
+	// Thrown back by an explosion, the zombie can't walk until it lands
+	if e.knockback.X != 0 || e.knockback.Y != 0 {
+		pushed, hit := moveCircle(e.pos, rl.Vector2Scale(e.knockback, float32(dt)), e.bodyRadius, globalBlocks)
+		e.pos = pushed
+		if hit {
+			e.knockback = rl.Vector2Zero()
+		} else {
+			e.knockback = rl.Vector2Scale(e.knockback, rl.Clamp(1-knockbackDrag*float32(dt), 0, 1))
+			if rl.Vector2Length(e.knockback) < enemySpeed {
+				e.knockback = rl.Vector2Zero()
+			}
+			return
+		}
+	}
+
+	// The zombie stands still while it swings
+	if e.windup > 0 {
+		return
+	}
 
 	// Calculate movement direction towards player
 	dir := rl.Vector2Subtract(playerPos, e.pos)
 	dir = rl.Vector2Normalize(dir)
 	dir = rl.Vector2Scale(dir, float32(dtspeed))
 
-	// Apply movement
-	e.pos = rl.Vector2Add(e.pos, dir)
-
-	// Check for collisions with blocks
-	if len(globalBlocks) > 0 {
-		enemyRect := rl.NewRectangle(e.pos.X-e.bodyRadius, e.pos.Y-e.bodyRadius, e.bodyRadius*2, e.bodyRadius*2)
-		for _, block := range globalBlocks {
-			blockRect := rl.NewRectangle(block.pos.X, block.pos.Y, block.width, block.height)
-			if rl.CheckCollisionRecs(enemyRect, blockRect) {
-				// On collision, revert to original position
-				e.pos = originalPos
-
-				// Try to navigate around the block - implement basic pathfinding
-				// Try moving only horizontally
-				horizontalPos := originalPos
-				horizontalPos.X += dir.X
-
-				// Check if horizontal movement would cause collision
-				horizontalRect := rl.NewRectangle(horizontalPos.X-e.bodyRadius, horizontalPos.Y-e.bodyRadius, e.bodyRadius*2, e.bodyRadius*2)
-				horizontalCollision := false
-				for _, b := range globalBlocks {
-					bRect := rl.NewRectangle(b.pos.X, b.pos.Y, b.width, b.height)
-					if rl.CheckCollisionRecs(horizontalRect, bRect) {
-						horizontalCollision = true
-						break
-					}
-				}
-
-				// Try moving only vertically
-				verticalPos := originalPos
-				verticalPos.Y += dir.Y
-
-				// Check if vertical movement would cause collision
-				verticalRect := rl.NewRectangle(verticalPos.X-e.bodyRadius, verticalPos.Y-e.bodyRadius, e.bodyRadius*2, e.bodyRadius*2)
-				verticalCollision := false
-				for _, b := range globalBlocks {
-					bRect := rl.NewRectangle(b.pos.X, b.pos.Y, b.width, b.height)
-					if rl.CheckCollisionRecs(verticalRect, bRect) {
-						verticalCollision = true
-						break
-					}
-				}
-
-				// If horizontal movement is possible, do that
-				if !horizontalCollision {
-					e.pos = horizontalPos
-				} else if !verticalCollision {
-					// Otherwise try vertical movement
-					e.pos = verticalPos
-				}
-				// If both cause collisions, the enemy stays in place for this frame
-
-				break
-			}
+	// Apply movement, sliding around blocks in the way
+	e.pos, _ = moveCircle(e.pos, dir, e.bodyRadius, globalBlocks)
+}
+
+// Start winding up when the player is in reach, returns true on the frame the attack lands.
+// The hit only lands if the player is still in reach, so attacks can be dodged
+func (e *Enemy) UpdateAttack(playerPos rl.Vector2, playerRadius float32, dt float64) bool {
+	if e.dying {
+		e.windup = 0
+		return false
+	}
+
+	if e.attackCooldown > 0 {
+		e.attackCooldown -= dt
+	}
+
+	inReach := rl.Vector2Distance(e.pos, playerPos) <= enemySize+playerRadius+enemyAttackReach
+
+	if e.windup > 0 {
+		e.windup -= dt
+		if e.windup > 0 {
+			return false
 		}
+		e.windup = 0
+		e.attackCooldown = enemyAttackCooldown
+		return inReach
+	}
+
+	if inReach && e.attackCooldown <= 0 {
+		e.windup = enemyWindupTime
+	}
+	return false
+}
+
+// Throw the enemy back, a stronger push replaces a weaker one
+func (e *Enemy) Knockback(velocity rl.Vector2) {
+	if e.dying {
+		return
+	}
+	if rl.Vector2Length(velocity) > rl.Vector2Length(e.knockback) {
+		e.knockback = velocity
 	}
 }
 
@@ -135,23 +169,63 @@ func (e *Enemy) Position() rl.Vector2 {
 	return e.pos
 }
 
+// Kill the enemy, it plays the death clip before being destroyed
+func (e *Enemy) Kill() {
+	if e.dying {
+		return
+	}
+	e.dying = true
+	e.anim.Restart(enemySheet, ClipDeath)
+}
+
+// Whether the death clip has finished and the enemy can turn into blood
+func (e *Enemy) DeathFinished() bool {
+	return e.dying && e.anim.Finished()
+}
+
+// Advance the animation and go back to walking after a flinch
+func (e *Enemy) UpdateAnimation(dt float64) {
+	e.anim.Update(dt)
+
+	if e.dying {
+		return
+	}
+
+	if e.anim.Clip() == ClipHurt && e.anim.Finished() {
+		e.anim.Play(enemySheet, ClipWalk)
+	}
+}
+
 func (e *Enemy) Render() {
+	// In the dark only lit enemies are drawn, the lighting system takes care of silhouettes
+	if lightingEnabled && e.visibility != VisibilityLit {
+		return
+	}
+
 	// Check if sprite was loaded successfully
-	if enemySprite.ID > 0 {
+	if enemySheet != nil {
 		// Size to draw the sprite (scale it according to enemySize)
-		spriteScale := enemySize / float32(enemySprite.Height) * 3.0
-		width := float32(enemySprite.Width) * spriteScale
-		height := float32(enemySprite.Height) * spriteScale
-
-		// Draw the sprite centered on enemy position
-		rl.DrawTexturePro(
-			enemySprite,
-			rl.NewRectangle(0, 0, float32(enemySprite.Width), float32(enemySprite.Height)),
-			rl.NewRectangle(e.pos.X-width/2, e.pos.Y-height/2, width, height),
-			rl.NewVector2(0, 0),
-			0,
-			rl.White,
-		)
+		height := enemySize * 3.0
+
+		// Fade the body out while it dies so it blends into the blood
+		tint := rl.White
+		if e.chillTimer > 0 {
+			tint = rl.SkyBlue
+		}
+		if e.windup > 0 {
+			tint = rl.Orange
+		}
+		if e.dying {
+			tint = rl.ColorAlpha(rl.White, 1-e.anim.Progress()*0.7)
+		}
+
+		// Draw the current frame centered on enemy position
+		enemySheet.Draw(&e.anim, e.pos, height, false, tint)
+
+		// Dead zombies don't need a health bar
+		if e.dying {
+			return
+		}
 
 		// Draw health bar above enemy
 		healthBarWidth := enemySize * 2
@@ -177,7 +251,18 @@ func (e *Enemy) Render() {
 		)
 	} else {
 		// Fallback to circle if sprite not loaded
-		rl.DrawCircle(int32(e.pos.X), int32(e.pos.Y), enemySize, rl.Red)
+		if e.dying {
+			rl.DrawCircle(int32(e.pos.X), int32(e.pos.Y), enemySize, rl.ColorAlpha(rl.Red, 1-e.anim.Progress()))
+			return
+		}
+		color := rl.Red
+		if e.chillTimer > 0 {
+			color = rl.SkyBlue
+		}
+		if e.windup > 0 {
+			color = rl.Orange
+		}
+		rl.DrawCircle(int32(e.pos.X), int32(e.pos.Y), enemySize, color)
 
 		// Draw health bar above enemy
 		healthBarWidth := enemySize * 2
@@ -204,8 +289,31 @@ func (e *Enemy) Render() {
 	}
 }
 
+// Draw the enemy as a dark shape, used when it's close but not lit
+func (e *Enemy) RenderSilhouette() {
+	silhouette := rl.NewColor(25, 0, 0, 150)
+
+	if enemySheet != nil {
+		enemySheet.Draw(&e.anim, e.pos, enemySize*3.0, false, silhouette)
+	} else {
+		rl.DrawCircle(int32(e.pos.X), int32(e.pos.Y), enemySize, silhouette)
+	}
+}
+
 func (e *Enemy) DealDamage(dmg float32) {
 	e.health -= dmg
+
+	// Flinch, unless the hit was fatal
+	if e.health > 0 && !e.dying {
+		e.anim.Restart(enemySheet, ClipHurt)
+	}
+}
+
+// Slow the enemy down for a while
+func (e *Enemy) Chill(duration float64) {
+	if duration > e.chillTimer {
+		e.chillTimer = duration
+	}
 }
 
 func (e *Enemy) Destroyed() bool {
@@ -216,6 +324,10 @@ func (e *Enemy) CheckCollision(other Collides) bool {
 	switch (other).(type) {
 	case *Enemy:
 		enemy := other.(*Enemy)
+		// Bodies don't push the crowd around
+		if e.dying || enemy.dying {
+			return false
+		}
 		return rl.CheckCollisionCircles(e.pos, e.bodyRadius, enemy.pos, enemy.bodyRadius)
 	}
 	return false
@@ -235,8 +347,8 @@ func (e *Enemy) Rearrange(other Collides) {
 			overlap := desiredDist - dist
 
 			// Add a small random jitter to prevent perfect symmetry that can cause flickering
-			jitterX := float32(rl.GetRandomValue(-10, 10)) * 0.01
-			jitterY := float32(rl.GetRandomValue(-10, 10)) * 0.01
+			jitterX := float32(RandomValue(-10, 10)) * 0.01
+			jitterY := float32(RandomValue(-10, 10)) * 0.01
 			jitter := rl.NewVector2(jitterX, jitterY)
 
 			// Get direction vector from this enemy to the other
@@ -259,87 +371,9 @@ func (e *Enemy) Rearrange(other Collides) {
 			pToColliding = rl.Vector2Add(pToColliding, jitter)
 			collidingToP = rl.Vector2Add(collidingToP, rl.Vector2Negate(jitter))
 
-			// Store original positions
-			originalPosE := e.pos
-			originalPosEnemy := enemy.pos
-
-			// Calculate new positions
-			newPosE := rl.Vector2Add(e.pos, collidingToP)
-			newPosEnemy := rl.Vector2Add(enemy.pos, pToColliding)
-
-			// Check if new positions would cause collision with blocks
-			eCollides := false
-			enemyCollides := false
-
-			// Check for block collisions only if we have global blocks
-			if len(globalBlocks) > 0 {
-				// Create enemy collision rectangles
-				eRect := rl.NewRectangle(newPosE.X-e.bodyRadius, newPosE.Y-e.bodyRadius, e.bodyRadius*2, e.bodyRadius*2)
-				enemyRect := rl.NewRectangle(newPosEnemy.X-enemy.bodyRadius, newPosEnemy.Y-enemy.bodyRadius, enemy.bodyRadius*2, enemy.bodyRadius*2)
-
-				for _, block := range globalBlocks {
-					blockRect := rl.NewRectangle(block.pos.X, block.pos.Y, block.width, block.height)
-
-					if rl.CheckCollisionRecs(eRect, blockRect) {
-						eCollides = true
-					}
-
-					if rl.CheckCollisionRecs(enemyRect, blockRect) {
-						enemyCollides = true
-					}
-				}
-			}
-
-			// Only apply movement if it doesn't cause block collision
-			if !eCollides {
-				e.pos = newPosE
-			}
-
-			if !enemyCollides {
-				enemy.pos = newPosEnemy
-			}
-
-			// If both enemies would collide with blocks, try a different approach
-			if eCollides && enemyCollides && len(globalBlocks) > 0 {
-				// Try moving them along the perpendicular direction
-				perpDir := rl.NewVector2(-dir.Y, dir.X)
-
-				// Calculate perpendicular push vectors
-				perpToE := rl.Vector2Scale(perpDir, moveAmount/2)
-				perpToEnemy := rl.Vector2Scale(rl.Vector2Negate(perpDir), moveAmount/2)
-
-				// Try new perpendicular positions
-				newPerpPosE := rl.Vector2Add(originalPosE, perpToE)
-				newPerpPosEnemy := rl.Vector2Add(originalPosEnemy, perpToEnemy)
-
-				// Check perpendicular positions for block collisions
-				ePerpRect := rl.NewRectangle(newPerpPosE.X-e.bodyRadius, newPerpPosE.Y-e.bodyRadius, e.bodyRadius*2, e.bodyRadius*2)
-				enemyPerpRect := rl.NewRectangle(newPerpPosEnemy.X-enemy.bodyRadius, newPerpPosEnemy.Y-enemy.bodyRadius, enemy.bodyRadius*2, enemy.bodyRadius*2)
-
-				ePerPCollides := false
-				enemyPerpCollides := false
-
-				for _, block := range globalBlocks {
-					blockRect := rl.NewRectangle(block.pos.X, block.pos.Y, block.width, block.height)
-
-					if rl.CheckCollisionRecs(ePerpRect, blockRect) {
-						ePerPCollides = true
-					}
-
-					if rl.CheckCollisionRecs(enemyPerpRect, blockRect) {
-						enemyPerpCollides = true
-					}
-				}
-
-				// Apply perpendicular movement if valid
-				if !ePerPCollides {
-					e.pos = newPerpPosE
-				}
-
-				if !enemyPerpCollides {
-					enemy.pos = newPerpPosEnemy
-				}
-			}
+			// Push both apart, sliding along blocks so the crowd can't shove a zombie into a wall
+			e.pos, _ = moveCircle(e.pos, collidingToP, e.bodyRadius, globalBlocks)
+			enemy.pos, _ = moveCircle(enemy.pos, pToColliding, enemy.bodyRadius, globalBlocks)
 		}
 	}
 }
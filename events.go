@@ -0,0 +1,148 @@
+package main
+
+import (
+	"reflect"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Events handled in one dispatch above this are dropped, a handler is publishing in a loop
+const MAX_EVENTS_PER_DISPATCH = 10000
+
+// Gameplay events, systems publish them and stats, effects, audio and UI react
+
+// ShotFired is a trigger pull that fired at least one projectile
+type ShotFired struct {
+	Weapon string
+	Count  int        // Projectiles fired
+	Sound  SoundID    // Sound of the weapon
+	Muzzle rl.Vector2 // End of the barrel
+	Dir    rl.Vector2
+	Origin rl.Vector2 // Where the shooter stands
+}
+
+// GrenadeThrown is a grenade leaving the player's hand
+type GrenadeThrown struct {
+	Pos rl.Vector2
+}
+
+// GrenadeExploded is a grenade going off
+type GrenadeExploded struct {
+	Kind   GrenadeKind
+	Pos    rl.Vector2
+	Radius float32
+	Kills  int // Enemies killed by the blast
+}
+
+// EnemyHit is damage dealt to an enemy
+type EnemyHit struct {
+	Enemy  *Enemy
+	Weapon string
+	Damage float32
+	Dir    rl.Vector2 // Direction the hit came from, blood sprays along it
+	Hit    bool       // Whether it counts as a hit for accuracy, a bullet going through a crowd hits once
+}
+
+// EnemyKilled is an enemy dropping to zero health
+type EnemyKilled struct {
+	Enemy  *Enemy
+	Weapon string
+	Level  int
+	Time   float64
+}
+
+// DamageTaken is damage dealt to the player
+type DamageTaken struct {
+	Amount float32
+	Level  int
+	Pos    rl.Vector2
+}
+
+// PickupCollected is the player walking over loot
+type PickupCollected struct {
+	Kind   string // "weapon", "ammo", "grenade", "coins" or "medkit"
+	Name   string // Type of ammo
+	Amount int
+	Pos    rl.Vector2
+	Time   float64
+}
+
+// WeaponEquipped is the player switching to another weapon
+type WeaponEquipped struct {
+	Name string
+	Time float64
+}
+
+// ReloadStarted is the player starting to reload
+type ReloadStarted struct {
+	Weapon string
+}
+
+// LevelCompleted is the last enemy of a level dying
+type LevelCompleted struct {
+	Level      int // The level that was just cleared
+	EnemyCount int // Enemies the level had
+	Time       float64
+}
+
+// LevelStarted is the transition to a new level ending
+type LevelStarted struct {
+	Level int
+	Time  float64
+}
+
+// EventBus queues events and hands them to the subscribers once per frame
+type EventBus struct {
+	handlers map[reflect.Type][]func(any)
+	queue    []any
+}
+
+// Shared event bus of the game
+var events = NewEventBus()
+
+// NewEventBus creates an empty event bus
+func NewEventBus() *EventBus {
+	return &EventBus{
+		handlers: make(map[reflect.Type][]func(any)),
+	}
+}
+
+// Call handler for every event of type T, handlers run in the order they subscribed
+func Subscribe[T any](bus *EventBus, handler func(T)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	bus.handlers[t] = append(bus.handlers[t], func(event any) {
+		handler(event.(T))
+	})
+}
+
+// Queue an event for the next dispatch
+func Publish[T any](bus *EventBus, event T) {
+	bus.queue = append(bus.queue, event)
+}
+
+// Hand every queued event to its subscribers in the order they were published
+func (bus *EventBus) Dispatch() {
+	// Events published by handlers go to the back of the queue and are handled in this same dispatch
+	for i := 0; i < len(bus.queue); i++ {
+		if i >= MAX_EVENTS_PER_DISPATCH {
+			rl.TraceLog(rl.LogWarning, "Too many events in one frame, dropping %d", len(bus.queue)-i)
+			break
+		}
+
+		event := bus.queue[i]
+		for _, handler := range bus.handlers[reflect.TypeOf(event)] {
+			handler(event)
+		}
+	}
+
+	bus.Clear()
+}
+
+// Drop every queued event, keeping the subscribers
+func (bus *EventBus) Clear() {
+	// Don't keep the events alive through the backing array
+	for i := range bus.queue {
+		bus.queue[i] = nil
+	}
+	bus.queue = bus.queue[:0]
+}
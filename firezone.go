@@ -0,0 +1,104 @@
+package main
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+var (
+	fireDuration      float64 = 5.0  // Seconds an incendiary grenade keeps burning
+	fireRadius        float32 = 110  // Size of the burning area
+	fireDamage        float32 = 25   // Damage of every tick to each enemy in the fire
+	fireTickInterval  float64 = 0.4  // Seconds between damage ticks
+	fireFlameInterval float64 = 0.05 // Seconds between flames rising from the fire
+)
+
+// FireZone is burning ground left by an incendiary grenade
+type FireZone struct {
+	pos         rl.Vector2
+	radius      float32
+	endTime     float64
+	nextTick    float64 // When the enemies inside take damage again
+	nextFlame   float64 // When the next flame rises
+	currentTime float64
+	destroyed   bool
+}
+
+// NewFireZone starts a fire at pos
+func NewFireZone(pos rl.Vector2, currentTime float64) *FireZone {
+	// Burning ground lights up the dark for as long as it lasts
+	lighting.AddLight(pos, fireRadius*2, 0.8, float32(fireDuration))
+
+	return &FireZone{
+		pos:         pos,
+		radius:      fireRadius,
+		endTime:     currentTime + fireDuration,
+		nextTick:    currentTime,
+		nextFlame:   currentTime,
+		currentTime: currentTime,
+	}
+}
+
+// Update burns the enemies standing in the fire
+func (f *FireZone) Update(currentTime float64, enemyList []*Enemy) {
+	f.currentTime = currentTime
+	if currentTime >= f.endTime {
+		f.destroyed = true
+		return
+	}
+
+	if currentTime >= f.nextTick {
+		f.nextTick = currentTime + fireTickInterval
+
+		for _, enemy := range enemyList {
+			if enemy.dying || rl.Vector2Distance(f.pos, enemy.pos) > f.radius {
+				continue
+			}
+			enemy.DealDamage(fireDamage)
+			Publish(events, EnemyHit{
+				Enemy:  enemy,
+				Weapon: GRENADE_WEAPON_NAME,
+				Damage: fireDamage,
+				Dir:    rl.NewVector2(0, -1),
+			})
+		}
+	}
+
+	// Flames rise from random spots of the fire
+	for currentTime >= f.nextFlame {
+		f.nextFlame += fireFlameInterval
+
+		angle := float64(rl.GetRandomValue(0, 360)) * math.Pi / 180
+		dist := f.radius * float32(rl.GetRandomValue(0, 100)) / 100
+		flamePos := rl.NewVector2(
+			f.pos.X+float32(math.Cos(angle))*dist,
+			f.pos.Y+float32(math.Sin(angle))*dist,
+		)
+		particles.Emit(EmitterFlames, flamePos, rl.NewVector2(0, -1))
+	}
+}
+
+// Render draws the glow of the burning ground, fading out as the fire dies
+func (f *FireZone) Render() {
+	fade := float32(1)
+	if left := f.endTime - f.currentTime; left < 1 {
+		fade = float32(left)
+	}
+
+	// Flicker a little so it doesn't look like a flat disc
+	flicker := 0.85 + 0.15*float32(math.Sin(f.currentTime*12))
+
+	rl.DrawCircleGradient(int32(f.pos.X), int32(f.pos.Y), f.radius,
+		rl.ColorAlpha(rl.Orange, 0.5*fade*flicker), rl.ColorAlpha(rl.Red, 0))
+}
+
+// Destroyed checks if the fire burned out
+func (f *FireZone) Destroyed() bool {
+	return f.destroyed
+}
+
+// Position returns the center of the fire
+func (f *FireZone) Position() rl.Vector2 {
+	return f.pos
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"math"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -11,42 +12,259 @@ var (
 	grenadeExplosionTime float64 = 2.0 // 2 seconds
 	grenadeExplosionSize float32 = 150 // Larger explosion radius (was 150)
 	grenadeDamage        float32 = 200 // Increased damage (was 100)
+
+	// Throwing and physics
+	grenadeMaxThrow       float32 = 500  // Farthest a grenade can be thrown
+	grenadeThrowSpeed     float32 = 650  // Horizontal speed of a throw in pixels per second
+	grenadeMinFlightTime  float32 = 0.25 // Even short throws spend this long in the air
+	grenadeHandHeight     float32 = 20   // Height the grenade leaves the hand at
+	grenadeGravity        float32 = 1400 // Pulls the grenade back to the ground
+	grenadeBounce         float32 = 0.4  // Vertical speed kept when hitting the ground
+	grenadeGroundFriction float32 = 0.6  // Horizontal speed kept when hitting the ground
+	grenadeWallBounce     float32 = 0.5  // Speed kept when bouncing off a block
+	grenadeRollFriction   float32 = 350  // Deceleration while rolling in pixels per second squared
+	grenadeMinBounceSpeed float32 = 80   // Slower landings stop bouncing and roll
+	grenadeBodyRadius     float32 = 8    // Size used for collisions with blocks
+
+	// The physics runs in fixed steps, the preview uses the same step so both follow one path
+	grenadePhysicsStep   float32 = 1.0 / 120
+	grenadePreviewPoints int     = 360 // Longest preview, in steps
+	grenadePreviewDots   int     = 8   // Steps between the dots of the preview
+
+	// Blast
+	grenadeEdgeDamage float32 = 0.2  // Fraction of the damage left at the edge of the blast
+	grenadeKnockback  float32 = 600  // Speed enemies are thrown back with at the center of the blast
+	friendlyFire      bool    = true // Whether the player's own grenades hurt the player
+	selfDamageScale   float32 = 0.5  // Fraction of the blast damage the player takes
 )
 
+// grenadeBody is the position of a grenade on the ground plus its height above it
+type grenadeBody struct {
+	pos rl.Vector2 // Position on the ground
+	vel rl.Vector2 // Speed along the ground
+	z   float32    // Height above the ground
+	vz  float32    // Vertical speed
+}
+
+// Body thrown from the player towards target, landing there on the first bounce
+func throwBody(from, target rl.Vector2) grenadeBody {
+	toTarget := rl.Vector2Subtract(target, from)
+	dist := rl.Vector2Length(toTarget)
+	if dist > grenadeMaxThrow {
+		toTarget = rl.Vector2Scale(toTarget, grenadeMaxThrow/dist)
+		dist = grenadeMaxThrow
+	}
+
+	flightTime := dist / grenadeThrowSpeed
+	if flightTime < grenadeMinFlightTime {
+		flightTime = grenadeMinFlightTime
+	}
+
+	// Solve z0 + vz*t - g*t^2/2 = 0 so the arc comes down on the target
+	return grenadeBody{
+		pos: from,
+		vel: rl.Vector2Scale(toTarget, 1/flightTime),
+		z:   grenadeHandHeight,
+		vz:  (grenadeGravity*flightTime*flightTime/2 - grenadeHandHeight) / flightTime,
+	}
+}
+
+// Whether the body has come to a stop on the ground
+func (b *grenadeBody) resting() bool {
+	return b.z == 0 && b.vz == 0 && b.vel.X == 0 && b.vel.Y == 0
+}
+
+// Advance the body by dt, bouncing off the ground and the blocks
+func (b *grenadeBody) step(dt float32, blocks []*Block) {
+	// Height
+	if b.z > 0 || b.vz != 0 {
+		b.vz -= grenadeGravity * dt
+		b.z += b.vz * dt
+
+		if b.z <= 0 {
+			b.z = 0
+			if -b.vz > grenadeMinBounceSpeed {
+				b.vz = -b.vz * grenadeBounce
+			} else {
+				b.vz = 0
+			}
+			b.vel = rl.Vector2Scale(b.vel, grenadeGroundFriction)
+		}
+	}
+
+	// Rolling friction once it's on the ground
+	if b.z == 0 && b.vz == 0 {
+		speed := rl.Vector2Length(b.vel)
+		if speed <= grenadeRollFriction*dt {
+			b.vel = rl.Vector2Zero()
+		} else {
+			b.vel = rl.Vector2Scale(b.vel, (speed-grenadeRollFriction*dt)/speed)
+		}
+	}
+
+	// Move one axis at a time so a block only reflects the speed it was hit with
+	b.pos.X += b.vel.X * dt
+	if grenadeHitsBlock(b.pos, blocks) {
+		b.pos.X -= b.vel.X * dt
+		b.vel.X = -b.vel.X * grenadeWallBounce
+	}
+	b.pos.Y += b.vel.Y * dt
+	if grenadeHitsBlock(b.pos, blocks) {
+		b.pos.Y -= b.vel.Y * dt
+		b.vel.Y = -b.vel.Y * grenadeWallBounce
+	}
+}
+
+func grenadeHitsBlock(pos rl.Vector2, blocks []*Block) bool {
+	for _, block := range blocks {
+		if rl.CheckCollisionCircleRec(pos, grenadeBodyRadius, block.GetRectangle()) {
+			return true
+		}
+	}
+	return false
+}
+
 // Grenade represents a timed explosive device
 type Grenade struct {
-	pos           rl.Vector2
-	placedTime    float64 // When the grenade was placed
+	grenadeBody
+	stepTime      float32 // Frame time not simulated yet, the physics runs in fixed steps
+	kind          GrenadeKind
+	radius        float32       // Blast radius, perks can make it bigger than the kind's
+	stuckTo       *Enemy        // Enemy a sticky grenade is attached to
+	stuckOffset   rl.Vector2    // Where on the enemy it stuck
+	shrapnel      []*Projectile // Thrown out by a frag grenade, main adds them to the world
+	fire          *FireZone     // Left by an incendiary grenade, main adds it to the world
+	placedTime    float64       // When the grenade was placed
 	hasExploded   bool
 	destroyed     bool
 	explosionTime float64 // When the explosion starts
 	currentTime   float64 // Current game time
+	lastBeep      float64 // When the fuse last beeped
+	kills         int     // Enemies killed by the explosion
+	selfDamage    float32 // Damage the blast dealt to the player, main applies it
 }
 
-// NewGrenade creates a new grenade
-func NewGrenade(pos rl.Vector2, currentTime float64) *Grenade {
+// NewGrenade throws a grenade from pos towards target, fuse is the time left before it explodes
+func NewGrenade(kind GrenadeKind, radius float32, pos, target rl.Vector2, fuse float64, currentTime float64) *Grenade {
 	return &Grenade{
-		pos:           pos,
+		grenadeBody:   throwBody(pos, target),
+		kind:          kind,
+		radius:        radius,
 		placedTime:    currentTime,
-		explosionTime: currentTime + grenadeExplosionTime,
+		explosionTime: currentTime + fuse,
 		currentTime:   currentTime,
+		lastBeep:      currentTime,
 		hasExploded:   false,
 		destroyed:     false,
 	}
 }
 
+// Path a grenade thrown now would follow until it explodes or stops, appended to points
+func PredictGrenadePath(from, target rl.Vector2, fuse float64, blocks []*Block, points []rl.Vector2) []rl.Vector2 {
+	body := throwBody(from, target)
+	steps := int(math.Ceil(fuse / float64(grenadePhysicsStep)))
+	if steps > grenadePreviewPoints {
+		steps = grenadePreviewPoints
+	}
+
+	for i := 0; i < steps && !body.resting(); i++ {
+		body.step(grenadePhysicsStep, blocks)
+		points = append(points, rl.NewVector2(body.pos.X, body.pos.Y-body.z))
+	}
+	return points
+}
+
+// Draw the predicted path, the end marks where the grenade will be when it goes off
+func DrawGrenadePreview(points []rl.Vector2, fuse float64, radius float32) {
+	for i, point := range points {
+		// Skipping steps keeps the dots apart
+		if i%grenadePreviewDots == 0 {
+			rl.DrawCircleV(point, 3, rl.ColorAlpha(rl.White, 0.7))
+		}
+	}
+
+	if len(points) > 0 {
+		end := points[len(points)-1]
+		rl.DrawCircleLines(int32(end.X), int32(end.Y), radius, rl.ColorAlpha(rl.Red, 0.6))
+
+		fuseText := fmt.Sprintf("%.1f", fuse)
+		fuseWidth := rl.MeasureText(fuseText, 20)
+		rl.DrawText(fuseText, int32(end.X)-fuseWidth/2, int32(end.Y)-30, 20, rl.Red)
+	}
+}
+
 // Update updates the grenade state
-func (g *Grenade) Update(currentTime float64, enemyList []*Enemy) {
+func (g *Grenade) Update(dt float64, currentTime float64, enemyList []*Enemy, blocks []*Block, playerPos rl.Vector2) {
 	g.currentTime = currentTime
 
+	// A sticky grenade grabs the first enemy it touches and rides along
+	if g.kind == GrenadeSticky && g.stuckTo == nil && !g.hasExploded {
+		for _, enemy := range enemyList {
+			if !enemy.dying && rl.CheckCollisionCircles(g.pos, grenadeBodyRadius, enemy.pos, enemySize) {
+				g.stuckTo = enemy
+				g.stuckOffset = rl.Vector2Subtract(g.pos, enemy.pos)
+				g.grenadeBody = grenadeBody{pos: g.pos}
+				break
+			}
+		}
+	}
+
+	// Fly, bounce and roll until it goes off
+	if g.stuckTo != nil {
+		g.pos = rl.Vector2Add(g.stuckTo.pos, g.stuckOffset)
+	} else if !g.hasExploded {
+		// Fixed steps whatever the frame rate, so the grenade lands where the preview showed.
+		// A long frame is cut short instead of simulating a burst of steps
+		g.stepTime = rl.Clamp(g.stepTime+float32(dt), 0, grenadePhysicsStep*8)
+		for g.stepTime >= grenadePhysicsStep && !g.resting() {
+			g.step(grenadePhysicsStep, blocks)
+			g.stepTime -= grenadePhysicsStep
+		}
+	}
+
+	// Beep while the fuse is burning, faster as the explosion gets closer
+	if !g.hasExploded {
+		beepInterval := 0.5
+		if g.explosionTime-currentTime < 0.6 {
+			beepInterval = 0.15
+		}
+		if currentTime-g.lastBeep >= beepInterval {
+			g.lastBeep = currentTime
+			PlaySFXAt(SoundGrenadeBeep, g.pos)
+		}
+	}
+
 	// Check if it's time to explode
 	if !g.hasExploded && g.currentTime >= g.explosionTime {
 		g.hasExploded = true
+		def := &grenadeDefs[g.kind]
 
 		// Damage all enemies within explosion radius
 		for _, enemy := range enemyList {
-			if rl.Vector2Distance(g.pos, enemy.pos) <= grenadeExplosionSize {
-				enemy.DealDamage(grenadeDamage)
+			// Bodies playing their death clip are already done for
+			if enemy.dying {
+				continue
+			}
+			falloff := blastFalloff(g.pos, enemy.pos, g.radius, blocks)
+			if falloff > 0 {
+				wasAlive := enemy.health > 0
+				damage := def.damage * falloff
+				dir := blastDirection(g.pos, enemy.pos)
+
+				enemy.DealDamage(damage)
+				enemy.Knockback(rl.Vector2Scale(dir, grenadeKnockback*falloff))
+				if g.kind == GrenadeCryo {
+					enemy.Chill(cryoSlowTime)
+				}
+				Publish(events, EnemyHit{
+					Enemy:  enemy,
+					Weapon: GRENADE_WEAPON_NAME,
+					Damage: damage,
+					Dir:    dir,
+				})
+				if wasAlive && enemy.health <= 0 {
+					g.kills++
+				}
 
 				// We should NOT mark enemies as destroyed here
 				// This breaks the game's enemy counting logic
@@ -54,40 +272,87 @@ func (g *Grenade) Update(currentTime float64, enemyList []*Enemy) {
 			}
 		}
 
-		// Set a delayed destruction time (show explosion for 0.5 seconds)
-		g.explosionTime = currentTime + 0.5
-	}
+		// Standing too close to your own grenade hurts
+		if friendlyFire {
+			g.selfDamage = def.damage * selfDamageScale * blastFalloff(g.pos, playerPos, g.radius, blocks)
+		}
+
+		// Sound, particles, scorch mark and light are left to the subscribers
+		Publish(events, GrenadeExploded{Kind: g.kind, Pos: g.pos, Radius: g.radius, Kills: g.kills})
+
+		// What the grenade leaves behind
+		switch g.kind {
+		case GrenadeIncendiary:
+			g.fire = NewFireZone(g.pos, currentTime)
+		case GrenadeFrag:
+			g.shrapnel = fragShrapnel(g.pos)
+		}
 
-	// Only destroy after showing explosion for a brief time
-	if g.hasExploded && currentTime > g.explosionTime {
 		g.destroyed = true
 	}
 }
 
-// Render draws the grenade and explosion effect
+// Fraction of the blast reaching target, full at the center and down to the edge damage at the
+// edge of the radius. Blocks between the two take the whole blast, so they can be used as cover
+func blastFalloff(center, target rl.Vector2, radius float32, blocks []*Block) float32 {
+	dist := rl.Vector2Distance(center, target)
+	if dist > radius || !lineOfSightClear(center, target, blocks) {
+		return 0
+	}
+	return 1 - (1-grenadeEdgeDamage)*dist/radius
+}
+
+// Direction from the blast to target, random when they're on top of each other
+func blastDirection(center, target rl.Vector2) rl.Vector2 {
+	dir := rl.Vector2Subtract(target, center)
+	if dir.X == 0 && dir.Y == 0 {
+		angle := float64(RandomValue(0, 360)) * math.Pi / 180
+		return rl.NewVector2(float32(math.Cos(angle)), float32(math.Sin(angle)))
+	}
+	return rl.Vector2Normalize(dir)
+}
+
+// Projectiles flying out all around pos
+func fragShrapnel(pos rl.Vector2) []*Projectile {
+	shrapnel := make([]*Projectile, 0, shrapnelCount)
+
+	// Turn the whole ring by a random amount so blasts don't all look alike
+	offset := float64(RandomValue(0, 360)) * math.Pi / 180
+	for i := 0; i < shrapnelCount; i++ {
+		angle := offset + 2*math.Pi*float64(i)/float64(shrapnelCount)
+		dir := rl.NewVector2(float32(math.Cos(angle)), float32(math.Sin(angle)))
+
+		p := NewProj(pos, rl.Vector2Add(pos, dir), shrapnelDamage)
+		p.weaponName = GRENADE_WEAPON_NAME
+		p.maxRange = shrapnelRange
+		p.shrapnel = true
+		shrapnel = append(shrapnel, p)
+	}
+	return shrapnel
+}
+
+// Render draws the grenade, the explosion is left to the particle system
 func (g *Grenade) Render() {
-	if g.hasExploded {
-		// Draw explosion with multiple layers for better effect
-		// Outer explosion layer
-		rl.DrawCircle(int32(g.pos.X), int32(g.pos.Y), grenadeExplosionSize, rl.ColorAlpha(rl.Orange, 0.4))
+	if !g.hasExploded {
+		// Shadow on the ground, smaller the higher the grenade is
+		shadowScale := 1 / (1 + g.z/100)
+		rl.DrawEllipse(int32(g.pos.X), int32(g.pos.Y), grenadeRadius*shadowScale, grenadeRadius*0.5*shadowScale, rl.ColorAlpha(rl.Black, 0.4))
 
-		// Middle explosion layer
-		rl.DrawCircle(int32(g.pos.X), int32(g.pos.Y), grenadeExplosionSize*0.7, rl.ColorAlpha(rl.Red, 0.6))
+		// The grenade itself is lifted by its height and looks bigger closer to the camera
+		drawPos := rl.NewVector2(g.pos.X, g.pos.Y-g.z)
+		radius := grenadeRadius * (1 + g.z/400)
 
-		// Inner explosion layer
-		rl.DrawCircle(int32(g.pos.X), int32(g.pos.Y), grenadeExplosionSize*0.4, rl.ColorAlpha(rl.Yellow, 0.8))
-	} else {
 		// Draw grenade
-		rl.DrawCircle(int32(g.pos.X), int32(g.pos.Y), grenadeRadius, rl.Gray)
+		rl.DrawCircleV(drawPos, radius, rl.Gray)
 
-		// Draw a small colored indicator
-		rl.DrawCircle(int32(g.pos.X), int32(g.pos.Y), grenadeRadius*0.5, rl.Red)
+		// Draw a small indicator in the color of its kind
+		rl.DrawCircleV(drawPos, radius*0.5, grenadeDefs[g.kind].color)
 
 		// Draw countdown timer
 		timeLeft := g.explosionTime - g.currentTime
 		countdown := fmt.Sprintf("%.1f", timeLeft)
 		countdownWidth := rl.MeasureText(countdown, 20)
-		rl.DrawText(countdown, int32(g.pos.X)-countdownWidth/2, int32(g.pos.Y)-10, 20, rl.White)
+		rl.DrawText(countdown, int32(drawPos.X)-countdownWidth/2, int32(drawPos.Y)-10, 20, rl.White)
 	}
 }
 
@@ -11,17 +11,19 @@ type GrenadePickup struct {
 	pos        rl.Vector2
 	createTime float64
 	destroyed  bool
+	kind       GrenadeKind
 	amount     int // Number of grenades to give
 	size       int // Size of pickup icon
 }
 
 // NewGrenadePickup creates a new grenade pickup
-func NewGrenadePickup(pos rl.Vector2, createTime float64) *GrenadePickup {
+func NewGrenadePickup(kind GrenadeKind, amount int, pos rl.Vector2, createTime float64) *GrenadePickup {
 	return &GrenadePickup{
 		pos:        pos,
 		createTime: createTime,
 		destroyed:  false,
-		amount:     2, // Give 2 grenades
+		kind:       kind,
+		amount:     amount,
 		size:       60,
 	}
 }
@@ -39,8 +41,8 @@ func (g *GrenadePickup) Render() {
 	radius := float32(g.size) / 4
 	rl.DrawCircle(int32(centerX), int32(centerY), radius, rl.Gray)
 
-	// Draw the red indicator
-	rl.DrawCircle(int32(centerX), int32(centerY), radius/2, rl.Red)
+	// Draw the indicator in the color of the kind
+	rl.DrawCircle(int32(centerX), int32(centerY), radius/2, grenadeDefs[g.kind].color)
 
 	// Draw text showing how many grenades
 	text := fmt.Sprintf("+%d", g.amount)
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// GrenadeKind is the type of explosive a grenade carries
+type GrenadeKind int
+
+const (
+	GrenadeExplosive GrenadeKind = iota
+	GrenadeIncendiary
+	GrenadeFrag
+	GrenadeCryo
+	GrenadeSticky
+	grenadeKindCount
+)
+
+// GrenadeDef describes one kind of grenade
+type GrenadeDef struct {
+	name       string
+	damage     float32 // Damage of the blast
+	radius     float32 // Reach of the blast
+	start      int     // Grenades the player starts with
+	lootAmount int     // Grenades in a pickup
+	color      rl.Color
+}
+
+var grenadeDefs = [grenadeKindCount]GrenadeDef{
+	GrenadeExplosive:  {name: "Explosive", damage: grenadeDamage, radius: grenadeExplosionSize, start: 3, lootAmount: 2, color: rl.Red},
+	GrenadeIncendiary: {name: "Incendiary", damage: 60, radius: 100, start: 0, lootAmount: 1, color: rl.Orange},
+	GrenadeFrag:       {name: "Frag", damage: 100, radius: 100, start: 0, lootAmount: 2, color: rl.Yellow},
+	GrenadeCryo:       {name: "Cryo", damage: 30, radius: 170, start: 0, lootAmount: 1, color: rl.SkyBlue},
+	GrenadeSticky:     {name: "Sticky", damage: 180, radius: 120, start: 0, lootAmount: 2, color: rl.Lime},
+}
+
+var (
+	shrapnelCount  int     = 16  // Projectiles thrown out by a frag grenade
+	shrapnelDamage float32 = 50  // Damage of each piece
+	shrapnelRange  float32 = 260 // Distance a piece flies before it falls
+	cryoSlowTime   float64 = 4.0 // Seconds enemies stay slowed by a cryo grenade
+	cryoSlowFactor float32 = 0.35
+)
+
+// Starting grenades of every kind
+func startingGrenades() [grenadeKindCount]int {
+	var grenades [grenadeKindCount]int
+	for kind, def := range grenadeDefs {
+		grenades[kind] = def.start
+	}
+	return grenades
+}
+
+// Grenades left of the selected kind
+func (p *player) Grenades() int {
+	return p.grenades[p.grenadeKind]
+}
+
+// Select the next kind of grenade the player has any of
+func (p *player) CycleGrenade() {
+	for i := 1; i < int(grenadeKindCount); i++ {
+		kind := (p.grenadeKind + GrenadeKind(i)) % grenadeKindCount
+		if p.grenades[kind] > 0 {
+			p.grenadeKind = kind
+			return
+		}
+	}
+}
+
+// Take a grenade of the selected kind, moving on to the next kind once it runs out
+func (p *player) UseGrenade() GrenadeKind {
+	kind := p.grenadeKind
+	p.grenades[kind]--
+	if p.grenades[kind] <= 0 {
+		p.CycleGrenade()
+	}
+	return kind
+}
+
+// Add picked up grenades, selecting them if the player had none of the selected kind
+func (p *player) AddGrenades(kind GrenadeKind, amount int) {
+	p.grenades[kind] += amount
+	if p.grenades[p.grenadeKind] == 0 {
+		p.grenadeKind = kind
+	}
+}
+
+// Pick the kind of a grenade pickup
+func RandomGrenadeKind() GrenadeKind {
+	return GrenadeKind(RandomValue(0, int32(grenadeKindCount)-1))
+}
+
+// Draw the carried grenades right aligned to x, the selected kind highlighted
+func (p *player) RenderGrenades(x, y int32) {
+	rowHeight := int32(24)
+	row := int32(0)
+
+	for kind, def := range grenadeDefs {
+		selected := GrenadeKind(kind) == p.grenadeKind
+		if p.grenades[kind] == 0 && !selected {
+			continue
+		}
+
+		text := fmt.Sprintf("%s: %d", def.name, p.grenades[kind])
+		color := rl.Gray
+		if selected {
+			text = "> " + text
+			color = def.color
+		}
+
+		textWidth := rl.MeasureText(text, 20)
+		rl.DrawText(text, x-textWidth, y+row*rowHeight, 20, color)
+		row++
+	}
+}
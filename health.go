@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+var (
+	armorAbsorption float32 = 0.6  // Share of the damage armor takes instead of health
+	regenEnabled    bool    = true // Heal slowly when out of combat
+	regenDelay      float64 = 5.0  // Seconds without being hit before regeneration starts
+	regenPerSecond  float32 = 15   // HP healed per second while regenerating
+
+	medkitHeal     int     = 250  // HP a medkit restores
+	medkitSize     int     = 50   // Size of the medkit icon
+	medkitLifetime float64 = 12.0 // Seconds before a medkit disappears
+
+	healthBarWidth int32 = 220
+)
+
+// MedkitPickup restores health when walked over
+type MedkitPickup struct {
+	pos        rl.Vector2
+	createTime float64
+	destroyed  bool
+	amount     int // HP restored
+}
+
+// NewMedkitPickup creates a new medkit
+func NewMedkitPickup(pos rl.Vector2, createTime float64) *MedkitPickup {
+	return &MedkitPickup{
+		pos:        pos,
+		createTime: createTime,
+		amount:     medkitHeal,
+	}
+}
+
+// Render draws a white box with a red cross
+func (m *MedkitPickup) Render() {
+	size := int32(medkitSize)
+	x, y := int32(m.pos.X), int32(m.pos.Y)
+
+	rl.DrawRectangle(x, y, size, size, rl.RayWhite)
+	rl.DrawRectangleLines(x, y, size, size, rl.Gray)
+
+	// Cross
+	arm := size / 5
+	rl.DrawRectangle(x+size/2-arm/2, y+arm, arm, size-arm*2, rl.Red)
+	rl.DrawRectangle(x+arm, y+size/2-arm/2, size-arm*2, arm, rl.Red)
+}
+
+// Destroyed checks if the medkit has been picked up or expired
+func (m *MedkitPickup) Destroyed() bool {
+	return m.destroyed
+}
+
+// Position returns the position of the medkit
+func (m *MedkitPickup) Position() rl.Vector2 {
+	return m.pos
+}
+
+// Heal up to the max HP, returns how much was healed
+func (p *player) Heal(amount int) int {
+	if amount > p.MaxHp()-p.CurrentHp {
+		amount = p.MaxHp() - p.CurrentHp
+	}
+	if amount < 0 {
+		amount = 0
+	}
+	p.CurrentHp += amount
+	return amount
+}
+
+// Put on armor up to the limit
+func (p *player) AddArmor(amount int) {
+	p.armor += amount
+	if p.armor > maxArmor {
+		p.armor = maxArmor
+	}
+}
+
+// Let the armor take its share of the damage, returns what's left for health
+func (p *player) absorbWithArmor(damage float32) float32 {
+	absorbed := int(damage * armorAbsorption)
+	if absorbed > p.armor {
+		absorbed = p.armor
+	}
+	p.armor -= absorbed
+	return damage - float32(absorbed)
+}
+
+// Heal slowly once the player hasn't been hit for a while
+func (p *player) UpdateRegen(dt float64, currentTime float64) {
+	if !regenEnabled || p.CurrentHp <= 0 || p.CurrentHp >= p.MaxHp() {
+		p.regenPool = 0
+		return
+	}
+	if currentTime-p.hitTime < regenDelay {
+		return
+	}
+
+	// Fractions of HP are kept for the next frame
+	p.regenPool += regenPerSecond * float32(dt)
+	heal := int(p.regenPool)
+	p.regenPool -= float32(heal)
+	p.Heal(heal)
+}
+
+// Whether the player is regenerating right now
+func (p *player) Regenerating(currentTime float64) bool {
+	return regenEnabled && p.CurrentHp > 0 && p.CurrentHp < p.MaxHp() && currentTime-p.hitTime >= regenDelay
+}
+
+// Draw the health bar with the armor bar under it, right aligned to x
+func (p *player) RenderHealthBars(x, y int32, currentTime float64) {
+	left := x - healthBarWidth
+
+	// Health
+	hpRatio := float32(p.CurrentHp) / float32(p.MaxHp())
+	rl.DrawRectangle(left, y, healthBarWidth, 22, rl.DarkGray)
+	rl.DrawRectangle(left, y, int32(float32(healthBarWidth)*hpRatio), 22, rl.Red)
+	rl.DrawRectangleLines(left, y, healthBarWidth, 22, rl.Black)
+
+	healthText := fmt.Sprintf("HP: %d/%d", p.CurrentHp, p.MaxHp())
+	if p.Regenerating(currentTime) {
+		healthText += " +"
+	}
+	rl.DrawText(healthText, left+6, y+2, 20, rl.White)
+
+	// Armor, only once the player has any
+	if p.armor > 0 {
+		armorRatio := float32(p.armor) / float32(maxArmor)
+		rl.DrawRectangle(left, y+24, healthBarWidth, 6, rl.DarkGray)
+		rl.DrawRectangle(left, y+24, int32(float32(healthBarWidth)*armorRatio), 6, rl.SkyBlue)
+	}
+}
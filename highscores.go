@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	RUN_HISTORY_FILE = "runs.dat"
+	HIGH_SCORE_COUNT = 10
+	MAX_NAME_LENGTH  = 12
+)
+
+// RunRecord is one finished run as stored on disk
+type RunRecord struct {
+	Name      string  `json:"name"`
+	Date      string  `json:"date"`
+	Seed      int64   `json:"seed"`
+	Score     int     `json:"score"`
+	Level     int     `json:"level"`
+	Kills     int     `json:"kills"`
+	Shots     int     `json:"shots"`
+	Damage    float32 `json:"damage"`
+	TimeAlive float64 `json:"timeAlive"`
+	Grenades  int     `json:"grenades"`
+	Weapon    string  `json:"weapon"`
+}
+
+// Path of a file in the game's save directory, falling back to the working directory
+func saveFilePath(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return name
+	}
+
+	dir = filepath.Join(dir, "survivor")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return name
+	}
+	return filepath.Join(dir, name)
+}
+
+// Write to a temporary file first and rename it over path, so a crash never leaves half a file behind
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Create the record of the run that just ended
+func NewRunRecord(stats GameStats, score int, weaponName string) RunRecord {
+	return RunRecord{
+		Date:      time.Now().Format(time.RFC3339),
+		Seed:      runSeed,
+		Score:     score,
+		Level:     stats.levelReached,
+		Kills:     stats.enemiesKilled,
+		Shots:     stats.shotsFired,
+		Damage:    stats.damageDealt,
+		TimeAlive: stats.timeAlive,
+		Grenades:  stats.grenadesThrown,
+		Weapon:    weaponName,
+	}
+}
+
+// Whether a beats b on the high score table
+func (a RunRecord) Beats(b RunRecord) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	if a.Level != b.Level {
+		return a.Level > b.Level
+	}
+	if a.Kills != b.Kills {
+		return a.Kills > b.Kills
+	}
+	return a.TimeAlive > b.TimeAlive
+}
+
+// RunHistory holds every stored run and the current high score table
+type RunHistory struct {
+	runs []RunRecord
+	top  []RunRecord
+}
+
+// Load every run in the history file, lines that are damaged are skipped
+func LoadRunHistory() *RunHistory {
+	history := &RunHistory{}
+
+	file, err := os.Open(saveFilePath(RUN_HISTORY_FILE))
+	if err != nil {
+		return history
+	}
+	defer file.Close()
+
+	// Each line is "<crc32> <json>" so a bad write only loses that one run
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		checksum, data, found := strings.Cut(scanner.Text(), " ")
+		if !found || checksum != fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(data))) {
+			continue
+		}
+
+		var run RunRecord
+		if err := json.Unmarshal([]byte(data), &run); err != nil {
+			continue
+		}
+		history.runs = append(history.runs, run)
+	}
+
+	history.top = topRuns(history.runs)
+	return history
+}
+
+// Store a finished run, returns its place in the high score table or -1
+func (h *RunHistory) Add(run RunRecord) int {
+	if err := saveRun(run); err != nil {
+		rl.TraceLog(rl.LogWarning, "Failed to save run: %s", err.Error())
+	}
+
+	h.runs = append(h.runs, run)
+	h.top = topRuns(h.runs)
+
+	for i, topRun := range h.top {
+		if topRun == run {
+			return i
+		}
+	}
+	return -1
+}
+
+// The high score table, best run first
+func (h *RunHistory) Top() []RunRecord {
+	return h.top
+}
+
+// Whether the run would make it into the high score table
+func (h *RunHistory) Qualifies(run RunRecord) bool {
+	if len(h.top) < HIGH_SCORE_COUNT {
+		return true
+	}
+	return run.Beats(h.top[len(h.top)-1])
+}
+
+// Append a run to the history file
+func saveRun(run RunRecord) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	// Appending never rewrites older runs, a crash can only damage the last line
+	file, err := os.OpenFile(saveFilePath(RUN_HISTORY_FILE), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "%08x %s\n", crc32.ChecksumIEEE(data), data)
+	return err
+}
+
+// Best runs first, at most HIGH_SCORE_COUNT of them
+func topRuns(runs []RunRecord) []RunRecord {
+	sorted := make([]RunRecord, len(runs))
+	copy(sorted, runs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Beats(sorted[j])
+	})
+
+	if len(sorted) > HIGH_SCORE_COUNT {
+		sorted = sorted[:HIGH_SCORE_COUNT]
+	}
+	return sorted
+}
+
+// Read typed characters into name, returns true when Enter is pressed
+func UpdateNameEntry(name *string) bool {
+	for char := rl.GetCharPressed(); char > 0; char = rl.GetCharPressed() {
+		if char >= 32 && char < 127 && len(*name) < MAX_NAME_LENGTH {
+			*name += string(rune(char))
+		}
+	}
+
+	if rl.IsKeyPressed(rl.KeyBackspace) && len(*name) > 0 {
+		*name = (*name)[:len(*name)-1]
+	}
+
+	return rl.IsKeyPressed(rl.KeyEnter)
+}
+
+// Draw the name entry prompt centered at y
+func DrawNameEntry(name string, centerX, y int32) {
+	title := "NEW HIGH SCORE! Enter your name:"
+	titleWidth := rl.MeasureText(title, 30)
+	rl.DrawText(title, centerX-titleWidth/2, y, 30, rl.Yellow)
+
+	// Blinking cursor
+	field := name
+	if int(rl.GetTime()*2)%2 == 0 {
+		field += "_"
+	}
+	fieldWidth := rl.MeasureText(field, 40)
+	rl.DrawText(field, centerX-fieldWidth/2, y+45, 40, rl.White)
+
+	hint := "Press ENTER to confirm"
+	hintWidth := rl.MeasureText(hint, 20)
+	rl.DrawText(hint, centerX-hintWidth/2, y+95, 20, rl.Gray)
+}
+
+// Draw the high score table centered at centerX, highlight is the row to mark (-1 for none)
+func DrawHighScoreTable(top []RunRecord, centerX, y int32, highlight int) {
+	title := "HIGH SCORES"
+	titleWidth := rl.MeasureText(title, 30)
+	rl.DrawText(title, centerX-titleWidth/2, y, 30, rl.Gold)
+
+	if len(top) == 0 {
+		empty := "No runs yet"
+		emptyWidth := rl.MeasureText(empty, 20)
+		rl.DrawText(empty, centerX-emptyWidth/2, y+45, 20, rl.Gray)
+		return
+	}
+
+	rowSpacing := int32(26)
+	tableWidth := int32(720)
+	left := centerX - tableWidth/2
+
+	columns := []int32{0, 50, 240, 350, 430, 520, 610}
+
+	drawTableRow(left, columns, y+45, rl.Gray, "#", "NAME", "SCORE", "LEVEL", "KILLS", "TIME", "WEAPON")
+
+	for i, run := range top {
+		name := run.Name
+		if name == "" {
+			name = "---"
+		}
+
+		minutes := int(run.TimeAlive) / 60
+		seconds := int(run.TimeAlive) % 60
+
+		color := rl.White
+		if i == highlight {
+			color = rl.Yellow
+		}
+
+		drawTableRow(left, columns, y+45+int32(i+1)*rowSpacing, color,
+			fmt.Sprintf("%d", i+1),
+			name,
+			fmt.Sprintf("%d", run.Score),
+			fmt.Sprintf("%d", run.Level),
+			fmt.Sprintf("%d", run.Kills),
+			fmt.Sprintf("%d:%02d", minutes, seconds),
+			run.Weapon,
+		)
+	}
+}
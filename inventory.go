@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Number of weapons the player can carry, the first slot always holds the pistol
+const INVENTORY_SLOTS = 4
+
+var (
+	weaponSwitchDelay  float64 = 0.35 // Seconds before a weapon that was switched to can fire
+	droppedPickupDelay float64 = 1.0  // Seconds before a dropped weapon can be picked up again
+)
+
+// Keys selecting each inventory slot
+var slotKeys = [INVENTORY_SLOTS]int32{rl.KeyOne, rl.KeyTwo, rl.KeyThree, rl.KeyFour}
+
+// WeaponSlot is one carried weapon and the bullets left in its magazine
+type WeaponSlot struct {
+	weapon   weapon
+	magazine int
+	occupied bool
+}
+
+// Inventory holds the carried weapons, the one in hand lives in player.currentWeapon
+type Inventory struct {
+	slots     [INVENTORY_SLOTS]WeaponSlot
+	current   int
+	readyTime float64 // When the weapon in hand is ready after a switch
+}
+
+// Start with only the pistol
+func NewInventory() Inventory {
+	inv := Inventory{}
+	inv.slots[0] = WeaponSlot{weapon: PISTOL, magazine: PISTOL.magazineSize, occupied: true}
+	return inv
+}
+
+// Slot holding the weapon, or -1
+func (inv *Inventory) find(w weapon) int {
+	for i, slot := range inv.slots {
+		if slot.occupied && slot.weapon == w {
+			return i
+		}
+	}
+	return -1
+}
+
+// First empty slot, or -1
+func (inv *Inventory) freeSlot() int {
+	for i, slot := range inv.slots {
+		if !slot.occupied {
+			return i
+		}
+	}
+	return -1
+}
+
+// Whether the player is still putting the last weapon away
+func (p *player) IsSwitching(currentTime float64) bool {
+	return currentTime < p.inventory.readyTime
+}
+
+// Take the weapon out of a slot, the magazine of the one in hand is kept in its slot
+func (p *player) EquipSlot(slot int, currentTime float64) bool {
+	inv := &p.inventory
+	if slot < 0 || slot >= INVENTORY_SLOTS || slot == inv.current || !inv.slots[slot].occupied {
+		return false
+	}
+
+	inv.slots[inv.current].magazine = p.currentMagazine
+	inv.current = slot
+	p.currentWeapon = inv.slots[slot].weapon
+	p.currentMagazine = inv.slots[slot].magazine
+
+	// Switching cancels a reload in progress
+	p.isReloading = false
+	inv.readyTime = currentTime + weaponSwitchDelay
+	return true
+}
+
+// Switch to the next (step 1) or previous (step -1) carried weapon
+func (p *player) CycleWeapon(step int, currentTime float64) {
+	inv := &p.inventory
+	for i := 1; i < INVENTORY_SLOTS; i++ {
+		slot := (inv.current + step*i + INVENTORY_SLOTS) % INVENTORY_SLOTS
+		if inv.slots[slot].occupied {
+			p.EquipSlot(slot, currentTime)
+			return
+		}
+	}
+}
+
+// Switch weapons with the number keys and the mouse wheel
+func (p *player) HandleWeaponInput(currentTime float64) {
+	for slot, key := range slotKeys {
+		if rl.IsKeyPressed(key) {
+			p.EquipSlot(slot, currentTime)
+		}
+	}
+
+	wheel := rl.GetMouseWheelMove()
+	if wheel > 0 {
+		p.CycleWeapon(-1, currentTime)
+	} else if wheel < 0 {
+		p.CycleWeapon(1, currentTime)
+	}
+}
+
+// Put a picked up weapon in the inventory and take it out, magazine is -1 for a new weapon.
+// When every slot is full the weapon in hand is swapped out and returned so it can be dropped
+func (p *player) PickUpWeapon(w weapon, magazine int, currentTime float64) (weapon, int, bool) {
+	inv := &p.inventory
+
+	// Already carried, keep the ammo in it
+	if slot := inv.find(w); slot >= 0 {
+		if w.usesAmmo {
+			if magazine < 0 {
+				magazine = w.magazineSize
+			}
+			p.AddAmmo(w.ammoType, magazine)
+		}
+		p.EquipSlot(slot, currentTime)
+		return weapon{}, 0, false
+	}
+
+	// A new weapon is loaded from the spare ammo
+	if magazine < 0 {
+		magazine = w.magazineSize
+		if w.usesAmmo {
+			magazine = p.TakeAmmo(w.ammoType, magazine)
+		}
+	}
+
+	slot := inv.freeSlot()
+	var dropped WeaponSlot
+	if slot < 0 {
+		// The pistol never leaves its slot, the last slot makes room instead
+		slot = inv.current
+		if slot == 0 {
+			slot = INVENTORY_SLOTS - 1
+		}
+		dropped = inv.slots[slot]
+		if slot == inv.current {
+			dropped.magazine = p.currentMagazine
+		}
+	}
+
+	inv.slots[slot] = WeaponSlot{weapon: w, magazine: magazine, occupied: true}
+
+	// The slot in hand was replaced, take the new weapon out directly
+	if slot == inv.current {
+		p.currentWeapon = w
+		p.currentMagazine = magazine
+		p.isReloading = false
+		inv.readyTime = currentTime + weaponSwitchDelay
+	} else {
+		p.EquipSlot(slot, currentTime)
+	}
+
+	return dropped.weapon, dropped.magazine, dropped.occupied
+}
+
+// Drop the weapon in hand and go back to the pistol, returns the weapon and its magazine
+func (p *player) DropWeapon(currentTime float64) (weapon, int, bool) {
+	inv := &p.inventory
+	if inv.current == 0 {
+		return weapon{}, 0, false
+	}
+
+	dropped := inv.slots[inv.current]
+	dropped.magazine = p.currentMagazine
+
+	inv.slots[inv.current] = WeaponSlot{}
+	inv.current = 0
+	p.currentWeapon = inv.slots[0].weapon
+	p.currentMagazine = inv.slots[0].magazine
+	p.isReloading = false
+	inv.readyTime = currentTime + weaponSwitchDelay
+
+	return dropped.weapon, dropped.magazine, true
+}
+
+// Draw the carried weapons as a list, the one in hand highlighted
+func (p *player) RenderInventory(x, y int32, currentTime float64) {
+	inv := &p.inventory
+	rowHeight := int32(28)
+
+	for i, slot := range inv.slots {
+		rowY := y + int32(i)*rowHeight
+
+		if !slot.occupied {
+			rl.DrawText(fmt.Sprintf("[%d] -", i+1), x, rowY, 20, rl.DarkGray)
+			continue
+		}
+
+		magazine := slot.magazine
+		if i == inv.current {
+			magazine = p.currentMagazine
+		}
+		text := fmt.Sprintf("[%d] %s %d/%d", i+1, slot.weapon.weaponName, magazine, slot.weapon.magazineSize)
+
+		color := rl.Gray
+		if i == inv.current {
+			color = rl.White
+			textWidth := rl.MeasureText(text, 20)
+			rl.DrawRectangle(x-4, rowY-3, textWidth+8, rowHeight-2, rl.ColorAlpha(rl.DarkGray, 0.6))
+			if p.IsSwitching(currentTime) {
+				color = rl.Yellow
+			}
+		}
+		rl.DrawText(text, x, rowY, 20, color)
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Enemy visibility under the lighting system
+const (
+	VisibilityLit        = iota // Drawn normally
+	VisibilitySilhouette        // Close to the player but in the dark, only an outline shows
+	VisibilityHidden            // Not drawn at all
+)
+
+var (
+	nightLevelInterval  int     = 3     // Every this many levels is played at night
+	ambientDarkness     float32 = 0.93  // Alpha of the darkness covering the arena
+	flashlightRange     float32 = 450   // How far the flashlight reaches
+	flashlightAngle     float32 = 50    // Full width of the flashlight cone in degrees
+	playerGlowRadius    float32 = 90    // Small light around the player so they can see their feet
+	silhouetteRange     float32 = 260   // Unlit enemies closer than this show as silhouettes
+	shadowLength        float32 = 3000  // Shadows are projected far enough to leave the screen
+	flashlightSegments  int     = 24    // Number of triangles in the cone
+	muzzleLightRadius   float32 = 180   // Radius of the light of a gunshot
+	muzzleLightDuration float32 = 0.06  // How long a gunshot lights the area
+	explosionLightScale float32 = 2.0   // Explosion light radius relative to the blast radius
+	explosionLightTime  float32 = 0.45  // How long an explosion lights the area
+	maxPointLights      int     = 64    // Point lights above this are dropped
+	lightingEnabled     bool    = false // Whether the current level is a night level
+)
+
+// Whether the level is played in the dark
+func isNightLevel(level int) bool {
+	return nightLevelInterval > 0 && level%nightLevelInterval == 0
+}
+
+// PointLight is a short-lived radial light (gunshots, explosions)
+type PointLight struct {
+	pos       rl.Vector2
+	radius    float32
+	intensity float32
+	life      float32
+	maxLife   float32
+}
+
+// LightingSystem draws darkness over the arena and cuts lights into it
+type LightingSystem struct {
+	target      rl.RenderTexture2D
+	lights      []PointLight
+	silhouettes []*Enemy     // Enemies drawn as silhouettes on top of the darkness
+	conePoints  []rl.Vector2 // Reused triangle fan for the flashlight cone
+
+	// Flashlight state captured when the light map was built
+	origin rl.Vector2
+	facing rl.Vector2
+}
+
+// Shared lighting system
+var lighting *LightingSystem
+
+// NewLightingSystem creates the light map covering the screen
+func NewLightingSystem(width, height int32) *LightingSystem {
+	return &LightingSystem{
+		target:     rl.LoadRenderTexture(width, height),
+		lights:     make([]PointLight, 0, maxPointLights),
+		conePoints: make([]rl.Vector2, 0, flashlightSegments+2),
+	}
+}
+
+// Add a light that fades out over duration seconds
+func (ls *LightingSystem) AddLight(pos rl.Vector2, radius, intensity, duration float32) {
+	if len(ls.lights) >= maxPointLights {
+		return
+	}
+	ls.lights = append(ls.lights, PointLight{pos: pos, radius: radius, intensity: intensity, life: duration, maxLife: duration})
+}
+
+// Fade the point lights and drop the dead ones
+func (ls *LightingSystem) Update(dt float64) {
+	alive := ls.lights[:0]
+	for _, light := range ls.lights {
+		light.life -= float32(dt)
+		if light.life > 0 {
+			alive = append(alive, light)
+		}
+	}
+	ls.lights = alive
+}
+
+// Remove every point light
+func (ls *LightingSystem) Clear() {
+	ls.lights = ls.lights[:0]
+	ls.silhouettes = ls.silhouettes[:0]
+}
+
+// Whether a point is lit by the flashlight, the player glow or a point light
+func (ls *LightingSystem) IsLit(pos rl.Vector2, blocks []*Block) bool {
+	toPos := rl.Vector2Subtract(pos, ls.origin)
+	dist := rl.Vector2Length(toPos)
+
+	if dist <= playerGlowRadius {
+		return true
+	}
+
+	for _, light := range ls.lights {
+		if rl.Vector2Distance(light.pos, pos) <= light.radius*0.7 {
+			return true
+		}
+	}
+
+	if dist > flashlightRange {
+		return false
+	}
+
+	// Inside the cone and not behind a block
+	cosAngle := rl.Vector2DotProduct(rl.Vector2Scale(toPos, 1/dist), ls.facing)
+	if cosAngle < float32(math.Cos(float64(flashlightAngle/2)*math.Pi/180)) {
+		return false
+	}
+	return lineOfSightClear(ls.origin, pos, blocks)
+}
+
+// Build the light map for this frame and decide which enemies can be seen
+func (ls *LightingSystem) Build(p *player, enemies []*Enemy, blocks []*Block) {
+	ls.origin = p.Pos
+	ls.facing = p.lookAt
+	if !p.lookAtSet {
+		ls.facing = rl.NewVector2(1, 0)
+	}
+
+	ls.silhouettes = ls.silhouettes[:0]
+	for _, e := range enemies {
+		switch {
+		case ls.IsLit(e.pos, blocks):
+			e.visibility = VisibilityLit
+		case rl.Vector2Distance(e.pos, p.Pos) <= silhouetteRange:
+			e.visibility = VisibilitySilhouette
+			ls.silhouettes = append(ls.silhouettes, e)
+		default:
+			e.visibility = VisibilityHidden
+		}
+	}
+
+	rl.BeginTextureMode(ls.target)
+	rl.DisableBackfaceCulling() // Shadow quads and the cone can have any winding
+
+	rl.ClearBackground(rl.ColorAlpha(rl.Black, ambientDarkness))
+
+	// Lights scale the darkness alpha down: dst = dst * (1 - src alpha)
+	rl.BeginBlendMode(rl.BlendCustom)
+	rl.SetBlendFactors(GL_ZERO, GL_ONE_MINUS_SRC_ALPHA, GL_FUNC_ADD)
+
+	// A wide dim cone with a narrower bright one inside gives a soft edge
+	ls.drawCone(flashlightAngle, flashlightRange, 0.6)
+	ls.drawCone(flashlightAngle*0.6, flashlightRange*0.9, 0.7)
+	rl.DrawCircleGradient(int32(ls.origin.X), int32(ls.origin.Y), playerGlowRadius, rl.ColorAlpha(rl.White, 0.8), rl.ColorAlpha(rl.White, 0))
+	rl.EndBlendMode()
+
+	// Blocks cast shadows from the flashlight by writing the darkness back
+	rl.BeginBlendMode(rl.BlendCustom)
+	rl.SetBlendFactors(GL_ONE, GL_ZERO, GL_FUNC_ADD)
+	shadow := rl.ColorAlpha(rl.Black, ambientDarkness)
+	for _, block := range blocks {
+		ls.drawShadow(block.GetRectangle(), shadow)
+	}
+	rl.EndBlendMode()
+
+	// Gunshots and explosions light everything around them
+	rl.BeginBlendMode(rl.BlendCustom)
+	rl.SetBlendFactors(GL_ZERO, GL_ONE_MINUS_SRC_ALPHA, GL_FUNC_ADD)
+	for _, light := range ls.lights {
+		alpha := light.intensity * light.life / light.maxLife
+		rl.DrawCircleGradient(int32(light.pos.X), int32(light.pos.Y), light.radius, rl.ColorAlpha(rl.White, alpha), rl.ColorAlpha(rl.White, 0))
+	}
+	rl.EndBlendMode()
+
+	rl.EndTextureMode()
+	rl.EnableBackfaceCulling()
+}
+
+// Draw the flashlight cone as a triangle fan
+func (ls *LightingSystem) drawCone(angle, length, alpha float32) {
+	points := append(ls.conePoints[:0], ls.origin)
+
+	facingAngle := math.Atan2(float64(ls.facing.Y), float64(ls.facing.X))
+	halfAngle := float64(angle/2) * math.Pi / 180
+
+	for i := 0; i <= flashlightSegments; i++ {
+		a := facingAngle - halfAngle + 2*halfAngle*float64(i)/float64(flashlightSegments)
+		points = append(points, rl.NewVector2(
+			ls.origin.X+float32(math.Cos(a))*length,
+			ls.origin.Y+float32(math.Sin(a))*length,
+		))
+	}
+
+	rl.DrawTriangleFan(points, rl.ColorAlpha(rl.White, alpha))
+	ls.conePoints = points
+}
+
+// Project every edge of the rectangle away from the light, the union is the shadow
+func (ls *LightingSystem) drawShadow(rect rl.Rectangle, color rl.Color) {
+	corners := [4]rl.Vector2{
+		rl.NewVector2(rect.X, rect.Y),
+		rl.NewVector2(rect.X+rect.Width, rect.Y),
+		rl.NewVector2(rect.X+rect.Width, rect.Y+rect.Height),
+		rl.NewVector2(rect.X, rect.Y+rect.Height),
+	}
+
+	for i := range corners {
+		a := corners[i]
+		b := corners[(i+1)%4]
+		farA := rl.Vector2Add(a, rl.Vector2Scale(rl.Vector2Normalize(rl.Vector2Subtract(a, ls.origin)), shadowLength))
+		farB := rl.Vector2Add(b, rl.Vector2Scale(rl.Vector2Normalize(rl.Vector2Subtract(b, ls.origin)), shadowLength))
+
+		rl.DrawTriangle(a, b, farB, color)
+		rl.DrawTriangle(a, farB, farA, color)
+	}
+}
+
+// Draw the darkness and the silhouettes of nearby unlit enemies
+func (ls *LightingSystem) Render() {
+	texture := ls.target.Texture
+
+	// Render textures are stored upside down, flip them with a negative height
+	rl.DrawTextureRec(
+		texture,
+		rl.NewRectangle(0, 0, float32(texture.Width), -float32(texture.Height)),
+		rl.NewVector2(0, 0),
+		rl.White,
+	)
+
+	for _, e := range ls.silhouettes {
+		e.RenderSilhouette()
+	}
+}
+
+// The lighting system lives for the whole game
+func (ls *LightingSystem) Destroyed() bool {
+	return false
+}
+
+func (ls *LightingSystem) Layer() RenderLayer {
+	return LayerLighting
+}
+
+// Free the light map
+func (ls *LightingSystem) Unload() {
+	rl.UnloadRenderTexture(ls.target)
+}
@@ -13,16 +13,36 @@ type WeaponLoot struct {
 	destroyed  bool
 	createTime float64  // Time when the loot was created
 	color      rl.Color // Color for visual distinction between weapons
+	magazine   int      // Bullets left in a dropped weapon, -1 for a new one
+	pickupTime float64  // Time from which it can be picked up
 }
 
 // AmmoLoot represents an ammo pickup
 type AmmoLoot struct {
+	ammoType   AmmoType
 	amount     int // Amount of ammo in this pickup
 	pos        rl.Vector2
 	destroyed  bool
 	createTime float64 // Time when the loot was created
 }
 
+// CoinLoot represents coins dropped by a zombie
+type CoinLoot struct {
+	value      int
+	pos        rl.Vector2
+	destroyed  bool
+	createTime float64 // Time when the coins were dropped
+}
+
+var (
+	coinDropChance int32   = 70 // Percent of zombies dropping coins
+	coinValueMin   int32   = 2  // Coins dropped on the first level
+	coinValueMax   int32   = 5
+	coinsPerLevel  float32 = 0.5  // Extra coins per drop for every level after the first
+	coinLifetime   float64 = 15.0 // Seconds before dropped coins disappear
+	coinRadius     float32 = 10
+)
+
 func NewWeaponLoot(weapon weapon, pos rl.Vector2, currentTime float64) *WeaponLoot {
 	// Set color based on weapon type
 	var color rl.Color
@@ -44,17 +64,34 @@ func NewWeaponLoot(weapon weapon, pos rl.Vector2, currentTime float64) *WeaponLo
 		pos:        pos,
 		createTime: currentTime,
 		color:      color,
+		magazine:   -1,
+		pickupTime: currentTime,
 	}
 }
 
-func NewAmmoLoot(amount int, pos rl.Vector2, currentTime float64) *AmmoLoot {
+func NewAmmoLoot(ammoType AmmoType, amount int, pos rl.Vector2, currentTime float64) *AmmoLoot {
 	return &AmmoLoot{
+		ammoType:   ammoType,
 		amount:     amount,
 		pos:        pos,
 		createTime: currentTime,
 	}
 }
 
+// Coins dropped by a zombie killed on a level, or nil when it drops none
+func NewCoinDrop(level int, pos rl.Vector2, currentTime float64) *CoinLoot {
+	if RandomValue(0, 99) >= coinDropChance {
+		return nil
+	}
+
+	value := int(RandomValue(coinValueMin, coinValueMax)) + int(coinsPerLevel*float32(level-1))
+	return &CoinLoot{
+		value:      value,
+		pos:        pos,
+		createTime: currentTime,
+	}
+}
+
 func (l *WeaponLoot) Destroyed() bool {
 	return l.destroyed
 }
@@ -86,14 +123,18 @@ func (l *AmmoLoot) Destroyed() bool {
 }
 
 func (l *AmmoLoot) Render() {
-	// Draw ammo box
-	rl.DrawRectangle(int32(l.pos.X), int32(l.pos.Y), int32(lootSize), int32(lootSize), rl.Yellow)
+	// Draw ammo box, colored by the type of rounds
+	def := &ammoDefs[l.ammoType]
+	rl.DrawRectangle(int32(l.pos.X), int32(l.pos.Y), int32(lootSize), int32(lootSize), def.color)
 
-	// Draw ammo text
-	label := fmt.Sprintf("%d", l.amount)
+	// Draw ammo type and amount
 	fontSize := 20
+	typeSize := rl.MeasureText(def.name, 16)
+	rl.DrawText(def.name, int32(l.pos.X+lootSize/2-float32(typeSize)/2), int32(l.pos.Y+lootSize/2-18), 16, rl.Black)
+
+	label := fmt.Sprintf("%d", l.amount)
 	textSize := rl.MeasureText(label, int32(fontSize))
-	rl.DrawText(label, int32(l.pos.X+lootSize/2-float32(textSize)/2), int32(l.pos.Y+lootSize/2-float32(fontSize)/2), int32(fontSize), rl.Black)
+	rl.DrawText(label, int32(l.pos.X+lootSize/2-float32(textSize)/2), int32(l.pos.Y+lootSize/2), int32(fontSize), rl.Black)
 }
 
 func (l *AmmoLoot) Position() rl.Vector2 {
@@ -107,3 +148,26 @@ func (l *AmmoLoot) CheckCollision(other Collides) bool {
 func (l *AmmoLoot) Rearrange(other Collides) {
 	// No rearrangement needed for ammo
 }
+
+func (l *CoinLoot) Destroyed() bool {
+	return l.destroyed
+}
+
+func (l *CoinLoot) Render() {
+	// Blink before disappearing
+	age := rl.GetTime() - l.createTime
+	if age > coinLifetime-3 && int(age*6)%2 == 0 {
+		return
+	}
+
+	rl.DrawCircleV(l.pos, coinRadius, rl.Gold)
+	rl.DrawCircleLines(int32(l.pos.X), int32(l.pos.Y), coinRadius, rl.Orange)
+
+	label := fmt.Sprintf("%d", l.value)
+	textSize := rl.MeasureText(label, 12)
+	rl.DrawText(label, int32(l.pos.X)-textSize/2, int32(l.pos.Y)-6, 12, rl.Black)
+}
+
+func (l *CoinLoot) Position() rl.Vector2 {
+	return l.pos
+}
@@ -18,7 +18,7 @@ var (
 	projSize          float32
 	lootSize          float32
 	backgroundTexture rl.Texture2D         // Background texture
-	bloodTexture      rl.Texture2D         // Blood texture
+	bloodTexture      rl.Texture2D         // Blood texture, stamped into the decal layer
 	gameOver          bool                 // Game over flag
 	gamePaused        bool         = false // Game paused flag
 )
@@ -29,72 +29,6 @@ type WorldItem interface {
 	Destroyed() bool
 }
 
-type Blood struct {
-	pos       rl.Vector2
-	destroyed bool
-	rotation  float32
-	scale     float32
-	opacity   float32
-	fading    bool
-}
-
-func NewBlood(pos rl.Vector2) *Blood {
-	// Random rotation between 0-360 degrees for variation
-	rotation := float32(rl.GetRandomValue(0, 359))
-	// Random scale between 0.8 and 1.2 for size variation
-	scale := 0.8 + float32(rl.GetRandomValue(0, 40))/100.0
-	// Full opacity
-	opacity := float32(1.0)
-
-	return &Blood{
-		pos:       pos,
-		destroyed: false,
-		rotation:  rotation,
-		scale:     scale,
-		opacity:   opacity,
-		fading:    false,
-	}
-}
-
-func (b *Blood) Update(dt float64) {
-	if b.fading {
-		// Fade out gradually when level ends
-		fadeSpeed := float32(dt) * 0.5 // Adjust speed as needed
-		b.opacity -= fadeSpeed
-
-		if b.opacity <= 0 {
-			b.opacity = 0
-			b.destroyed = true
-		}
-	}
-}
-
-func (b *Blood) Render() {
-	if bloodTexture.ID > 0 {
-		// Size to draw the blood
-		baseSize := float32(50)
-		width := baseSize * b.scale
-		height := baseSize * b.scale
-
-		// Draw the blood sprite with rotation and transparency
-		rl.DrawTexturePro(
-			bloodTexture,
-			rl.NewRectangle(0, 0, float32(bloodTexture.Width), float32(bloodTexture.Height)),
-			rl.NewRectangle(b.pos.X-width/2, b.pos.Y-height/2, width, height),
-			rl.NewVector2(width/2, height/2),
-			b.rotation,
-			rl.ColorAlpha(rl.White, b.opacity),
-		)
-	} else {
-		// Fallback if texture not loaded
-		rl.DrawCircle(int32(b.pos.X), int32(b.pos.Y), 10, rl.ColorAlpha(rl.Maroon, b.opacity))
-	}
-}
-
-func (b *Blood) Destroyed() bool {
-	return b.destroyed
-}
-
 func RemoveIndex[T any](s []T, index int) []T {
 	return append(s[:index], s[index+1:]...)
 }
@@ -112,13 +46,6 @@ func UpdateWorldItems[T WorldItem](worldItems []T) []T {
 	return worldItems
 }
 
-func RandomPointInCircle(radius float32) rl.Vector2 {
-	x := float32(rl.GetRandomValue(-100, 100))
-	y := float32(rl.GetRandomValue(-100, 100))
-	vector := rl.Vector2Scale((rl.Vector2Normalize(rl.NewVector2(x, y))), radius)
-	return vector
-}
-
 // Calculate enemies for level: base amount + level increment
 func getEnemiesForLevel(level int) int {
 	baseEnemies := 5
@@ -134,9 +61,10 @@ func getEnemyHealthForLevel(level int) float32 {
 }
 
 // Calculate enemy damage for level
+// Damage of one zombie attack
 func getEnemyDamageForLevel(level int) float32 {
-	baseDamage := float32(10)
-	damageIncreasePerLevel := float32(5)
+	baseDamage := float32(60)
+	damageIncreasePerLevel := float32(10)
 	return baseDamage + float32(level-1)*damageIncreasePerLevel
 }
 
@@ -191,62 +119,6 @@ func (b *Block) GetRectangle() rl.Rectangle {
 	return rl.NewRectangle(b.pos.X, b.pos.Y, b.width, b.height)
 }
 
-type ImpactEffect struct {
-	pos         rl.Vector2
-	radius      float32
-	maxRadius   float32
-	color       rl.Color
-	lifeTime    float32
-	maxLifeTime float32
-	destroyed   bool
-}
-
-func NewImpactEffect(pos rl.Vector2, color rl.Color) *ImpactEffect {
-	return &ImpactEffect{
-		pos:         pos,
-		radius:      0,
-		maxRadius:   10.0,
-		color:       color,
-		lifeTime:    0,
-		maxLifeTime: 0.5, // half a second
-		destroyed:   false,
-	}
-}
-
-func (i *ImpactEffect) Update(dt float64) {
-	i.lifeTime += float32(dt)
-
-	// Calculate current radius based on lifetime
-	progress := i.lifeTime / i.maxLifeTime
-	if progress < 0.5 {
-		// Expanding phase
-		i.radius = i.maxRadius * (progress * 2)
-	} else {
-		// Shrinking phase
-		i.radius = i.maxRadius * (1.0 - (progress-0.5)*2)
-	}
-
-	// Destroy when lifetime is over
-	if i.lifeTime >= i.maxLifeTime {
-		i.destroyed = true
-	}
-}
-
-func (i *ImpactEffect) Render() {
-	// Calculate alpha based on lifetime
-	progress := i.lifeTime / i.maxLifeTime
-	alpha := float32(1.0 - progress)
-
-	// Draw circle with fade-out effect
-	color := i.color
-	color.A = uint8(255 * alpha)
-	rl.DrawCircle(int32(i.pos.X), int32(i.pos.Y), i.radius, color)
-}
-
-func (i *ImpactEffect) Destroyed() bool {
-	return i.destroyed
-}
-
 // Calculate enemy spawn delay for level
 func getEnemySpawnDelayForLevel(level int) float64 {
 	baseDelay := 1.0
@@ -266,9 +138,17 @@ type GameStats struct {
 	levelReached   int
 	enemiesKilled  int
 	shotsFired     int
+	hits           int
 	damageDealt    float32
 	timeAlive      float64
 	grenadesThrown int
+	reloads        int
+
+	// Breakdowns for the game over screen and the JSON export
+	pickups     map[string]int
+	weapons     map[string]*WeaponStats
+	weaponOrder []string // Weapons in the order they were first used
+	levels      map[int]*LevelStats
 }
 
 // Initialize game stats
@@ -283,6 +163,9 @@ func resetGameStats() {
 		damageDealt:    0,
 		timeAlive:      0,
 		grenadesThrown: 0,
+		pickups:        make(map[string]int),
+		weapons:        make(map[string]*WeaponStats),
+		levels:         make(map[int]*LevelStats),
 	}
 }
 
@@ -296,9 +179,33 @@ func main() {
 	rl.SetExitKey(0) // Disable the default ESC key for window closing
 	rl.SetTargetFPS(60)
 
+	// Initialize audio, the game keeps running silently without a device
+	InitAudio()
+	InitMusic()
+	LoadSettings()
+
+	// Achievement definitions and the unlocks of earlier runs
+	InitAchievements()
+	InitPerks()
+	InitShop()
+	InitDropTables()
+	InitEmitters()
+
+	// Systems that react to gameplay events, handlers run in this order
+	subscribeStats(events)
+	subscribeScoring(events)
+	subscribeAchievements(events)
+	subscribeAudio(events)
+	subscribeMusic(events)
+	subscribeEffects(events)
+	subscribeNotifications(events)
+
 	// Print debugging info about sprite loading
 	rl.TraceLog(rl.LogWarning, "Looking for sprite files: player_left.png, player_right.png, and zombie.png")
 
+	// Initialize player sprites, shared by every run
+	InitPlayerSprites()
+
 	// Initialize enemy sprite
 	InitEnemySprite()
 
@@ -330,10 +237,24 @@ func main() {
 	var worldBodies []Collides
 	var loots []*WeaponLoot
 	var ammoLoots []*AmmoLoot
+	var coinLoots []*CoinLoot
 	var grenadePickups []*GrenadePickup
-	var bloodList []*Blood
+	var medkits []*MedkitPickup
+	var fireZones []*FireZone
 	var blocks []*Block
-	var impacts []*ImpactEffect
+	var spawner Spawner // Picks where zombies and pickups appear, set up with the map
+
+	// Everything in the world is drawn through the layered render queue
+	var renderQueue RenderQueue
+
+	// Shared pool for every particle effect
+	particles = NewParticleSystem(MAX_PARTICLES)
+
+	// Blood, scorch marks and bullet holes are baked into one texture
+	decals = NewDecalLayer(int32(w), int32(h))
+
+	// Darkness, flashlight and point lights for night levels
+	lighting = NewLightingSystem(int32(w), int32(h))
 
 	// Level system variables
 	currentLevel := 1
@@ -343,6 +264,69 @@ func main() {
 	levelCompleted := false
 	levelCompletedTime := 0.0
 	levelCompletedDuration := 2.0 // Show level complete message for 2 seconds
+	var perkDraft PerkDraft       // Perks offered while the level complete message is up
+	var shop Shop                 // Opens once a perk was picked, the next level waits for it to close
+	var lootDropper LootDropper   // Rolls what killed enemies drop
+
+	// Throw a weapon out of the inventory in front of the player, it keeps its magazine
+	dropWeaponLoot := func(w weapon, magazine int, currentTime float64) {
+		center := rl.Vector2Add(player.Pos, rl.Vector2Scale(player.lookAt, lootSize+playerSize))
+		center = spawner.Nudge(center, pickupClearance(lootSize))
+		loot := NewWeaponLoot(w, rl.NewVector2(center.X-lootSize/2, center.Y-lootSize/2), currentTime)
+		loot.magazine = magazine
+		loot.pickupTime = currentTime + droppedPickupDelay
+		worldBodies = append(worldBodies, loot)
+		worldItems = append(worldItems, loot)
+		loots = append(loots, loot)
+	}
+
+	// Put a rolled drop in the world centered on pos, moved out of any block it would overlap
+	spawnDrop := func(drop LootDrop, pos rl.Vector2, currentTime float64) {
+		switch drop.Kind {
+		case DropWeapon:
+			pos = spawner.Nudge(pos, pickupClearance(lootSize))
+			loot := NewWeaponLoot(drop.weapon, rl.NewVector2(pos.X-lootSize/2, pos.Y-lootSize/2), currentTime)
+			worldBodies = append(worldBodies, loot)
+			worldItems = append(worldItems, loot)
+			loots = append(loots, loot)
+		case DropAmmo:
+			pos = spawner.Nudge(pos, pickupClearance(lootSize))
+			ammo := NewAmmoLoot(drop.ammoType, drop.amount, rl.NewVector2(pos.X-lootSize/2, pos.Y-lootSize/2), currentTime)
+			worldBodies = append(worldBodies, ammo)
+			worldItems = append(worldItems, ammo)
+			ammoLoots = append(ammoLoots, ammo)
+		case DropGrenade:
+			pickup := NewGrenadePickup(drop.grenadeKind, drop.amount, pos, currentTime)
+			pos = spawner.Nudge(pos, pickupClearance(float32(pickup.size)))
+			pickup.pos = rl.NewVector2(pos.X-float32(pickup.size)/2, pos.Y-float32(pickup.size)/2)
+			worldItems = append(worldItems, pickup)
+			grenadePickups = append(grenadePickups, pickup)
+		case DropMedkit:
+			pos = spawner.Nudge(pos, pickupClearance(float32(medkitSize)))
+			medkit := NewMedkitPickup(rl.NewVector2(pos.X-float32(medkitSize)/2, pos.Y-float32(medkitSize)/2), currentTime)
+			medkit.amount = drop.amount
+			worldItems = append(worldItems, medkit)
+			medkits = append(medkits, medkit)
+		}
+	}
+
+	// Kill an enemy and let everyone know, blood is added once the death clip has finished
+	killEnemy := func(e *Enemy, weaponName string, currentTime float64) {
+		e.Kill()
+		enemiesInPlay--
+		Publish(events, EnemyKilled{Enemy: e, Weapon: weaponName, Level: currentLevel, Time: currentTime})
+
+		// Most zombies drop a few coins
+		if coins := NewCoinDrop(currentLevel, e.pos, currentTime); coins != nil {
+			worldItems = append(worldItems, coins)
+			coinLoots = append(coinLoots, coins)
+		}
+
+		// Some drop a pickup from their drop table where they fell
+		if drop, ok := lootDropper.Roll(e, currentLevel, &player); ok {
+			spawnDrop(drop, e.pos, currentTime)
+		}
+	}
 
 	lastTime := rl.GetTime()
 	lastEnemySpawn := lastTime
@@ -354,10 +338,17 @@ func main() {
 	lastShoot := lastTime
 	lastGrenade := lastTime
 	grenadeDelay := 1.0
+	cookingGrenade := false // Whether 'E' is held with a grenade in hand
+	cookStart := lastTime   // When the grenade in hand was primed
+	var grenadePreview []rl.Vector2
+	holdFire := false // Set while a menu click is held, so it doesn't turn into a shot once the menu closes
 
 	lastGrenadePickupSpawn := lastTime
 	grenadePickupDelay := 10.0 // Spawn grenade pickup every 10 seconds
 
+	lastMedkitSpawn := lastTime
+	medkitSpawnDelay := 15.0 // Spawn a medkit every 15 seconds
+
 	w = rl.GetMonitorWidth(display)
 	h = rl.GetMonitorHeight(display)
 
@@ -392,6 +383,11 @@ func main() {
 	// Update global blocks reference for enemy collision
 	UpdateGlobalBlocks(blocks)
 
+	// Zombies come out anywhere away from the edges, pickups anywhere on the screen
+	spawner = NewSpawner(rl.NewRectangle(0, 0, float32(w), float32(h)), blocks)
+	spawner.AddZone(SpawnEnemies, rl.NewRectangle(spawnEdgeMargin, spawnEdgeMargin, float32(w)-spawnEdgeMargin*2, float32(h)-spawnEdgeMargin*2))
+	spawner.AddZone(SpawnLoot, rl.NewRectangle(0, 0, float32(w), float32(h)))
+
 	// Initialize game stats and reset game over flag
 	resetGameStats()
 	gameOver = false
@@ -402,15 +398,98 @@ func main() {
 
 	var showGrid bool = false;
 
+	// Title screen and high scores
+	runHistory := LoadRunHistory()
+	showTitle := true
+	enteringName := false  // Typing a name for a new high score
+	statsTab := TabSummary // Selected tab of the game over screen
+	exportMessage := ""    // Result of the last stats export
+	playerName := ""
+	var lastRun RunRecord
+	lastRunRank := -1 // Place of the last run in the high score table
+
+	showVolume := false // Whether the title screen shows the volume settings instead of the high scores
+	volumeRow := 0      // Selected row of the volume settings
+
 	for !rl.WindowShouldClose() {
 		currentTime := rl.GetTime()
 		dt := currentTime - lastTime
 
 		// Handle ESC key for game pause
-		if rl.IsKeyPressed(rl.KeyEscape) {
+		if rl.IsKeyPressed(rl.KeyEscape) && !showTitle {
 			gamePaused = !gamePaused
 		}
 
+		// Let the soundtrack follow the fight, it keeps streaming while paused
+		if gameOver {
+			SetMusicIntensity(IntensitySilence)
+		} else {
+			hpRatio := float32(player.CurrentHp) / float32(player.MaxHp())
+			SetMusicIntensity(musicIntensityFor(enemiesInPlay, hpRatio, levelCompleted))
+		}
+		UpdateMusic(dt, gamePaused)
+
+		// Title screen with the high score table, the game starts on ENTER
+		if showTitle {
+			rl.BeginDrawing()
+			rl.ClearBackground(rl.Black)
+
+			titleText := "SURVIVOR"
+			titleWidth := rl.MeasureText(titleText, 80)
+			rl.DrawText(titleText, int32(w)/2-titleWidth/2, int32(h)/8, 80, rl.Red)
+
+			// V swaps the high score table for the volume settings
+			if rl.IsKeyPressed(rl.KeyV) {
+				showVolume = !showVolume
+			}
+			if showVolume {
+				UpdateVolumeSettings(&volumeRow)
+				DrawVolumeSettings(volumeRow, int32(w)/2, int32(h)/8+140)
+			} else {
+				DrawHighScoreTable(runHistory.Top(), int32(w)/2, int32(h)/8+140, -1)
+			}
+
+			unlocked, total := achievements.Progress()
+			achievementsText := fmt.Sprintf("Achievements: %d / %d", unlocked, total)
+			achievementsWidth := rl.MeasureText(achievementsText, 20)
+			rl.DrawText(achievementsText, int32(w)/2-achievementsWidth/2, int32(h)-175, 20, rl.Gold)
+
+			// Friendly fire can be switched before a run starts
+			if rl.IsKeyPressed(rl.KeyF) {
+				friendlyFire = !friendlyFire
+			}
+			friendlyFireText := "F: Friendly fire OFF"
+			if friendlyFire {
+				friendlyFireText = "F: Friendly fire ON"
+			}
+			friendlyFireWidth := rl.MeasureText(friendlyFireText, 20)
+			rl.DrawText(friendlyFireText, int32(w)/2-friendlyFireWidth/2, int32(h)-150, 20, rl.Gray)
+
+			volumeText := "V: Volume settings"
+			if showVolume {
+				volumeText = "V: High scores"
+			}
+			volumeWidth := rl.MeasureText(volumeText, 20)
+			rl.DrawText(volumeText, int32(w)/2-volumeWidth/2, int32(h)-125, 20, rl.Gray)
+
+			startText := "Press ENTER to start"
+			startWidth := rl.MeasureText(startText, 30)
+			rl.DrawText(startText, int32(w)/2-startWidth/2, int32(h)-100, 30, rl.White)
+
+			rl.EndDrawing()
+
+			if rl.IsKeyPressed(rl.KeyEnter) {
+				showTitle = false
+				NewRunSeed()
+				achievements.StartRun()
+				gameStartTime = currentTime
+				scoring.Reset()
+			}
+
+			lastTime = currentTime
+			continue
+		}
+
 		if rl.IsKeyPressed(rl.KeyK) {
 			showGrid = !showGrid;
 		}
@@ -420,58 +499,51 @@ func main() {
 			mousePosition := rl.GetMousePosition()
 			player.LookAt(mousePosition)
 
-			// Process player movement and check for collisions with blocks
-			dtSpeed := playerSpeed * float32(dt)
+			// Process player movement and check for collisions with blocks, a dying player stays put
 			moveDirection := rl.Vector2Zero()
 
-			if rl.IsKeyDown(rl.KeyA) {
-				moveDirection.X -= 1
-				player.facingLeft = true
-			}
+			if !player.dying {
+				if rl.IsKeyDown(rl.KeyA) {
+					moveDirection.X -= 1
+					player.facingLeft = true
+				}
 
-			if rl.IsKeyDown(rl.KeyD) {
-				moveDirection.X += 1
-				player.facingLeft = false
-			}
+				if rl.IsKeyDown(rl.KeyD) {
+					moveDirection.X += 1
+					player.facingLeft = false
+				}
+
+				if rl.IsKeyDown(rl.KeyW) {
+					moveDirection.Y -= 1
+				}
 
-			if rl.IsKeyDown(rl.KeyW) {
-				moveDirection.Y -= 1
+				if rl.IsKeyDown(rl.KeyS) {
+					moveDirection.Y += 1
+				}
 			}
 
-			if rl.IsKeyDown(rl.KeyS) {
-				moveDirection.Y += 1
+			// Sprint while holding shift, a roll takes over the movement until it ends.
+			// No rolling while the perk draft or the shop is up
+			menuOpen := perkDraft.Active() || shop.Active()
+			moving := moveDirection.X != 0 || moveDirection.Y != 0
+			dtSpeed := player.MoveSpeed() * player.SprintMultiplier(moving, dt, currentTime) * float32(dt)
+			if rl.IsKeyPressed(dodgeKey) && !menuOpen && !player.dying {
+				player.StartDodge(moveDirection, currentTime)
 			}
+			if player.Dodging(currentTime) {
+				moveDirection = player.dodgeDir
+				dtSpeed = dodgeSpeed * float32(dt)
+			}
+			player.UpdateStamina(dt, currentTime)
 
 			// Normalize movement vector if moving diagonally
 			if moveDirection.X != 0 || moveDirection.Y != 0 {
 				moveDirection = rl.Vector2Normalize(moveDirection)
 
-				// Store current position before moving
-				oldPos := player.Pos
-
-				// Apply movement
-				player.Pos.X += moveDirection.X * dtSpeed
-				player.Pos.Y += moveDirection.Y * dtSpeed
-
-				// Calculate player's collision rectangle
-				// Adjust these values based on your player's visual size
+				// Apply movement, sliding along any block in the way
 				playerHalfWidth := playerSize * 0.7
 				playerHalfHeight := playerSize * 0.7
-				playerRect := rl.NewRectangle(
-					player.Pos.X-playerHalfWidth,
-					player.Pos.Y-playerHalfHeight,
-					playerHalfWidth*2,
-					playerHalfHeight*2,
-				)
-
-				// Check for collisions with blocks
-				for _, block := range blocks {
-					if rl.CheckCollisionRecs(playerRect, block.GetRectangle()) {
-						// Collision detected - revert to previous position
-						player.Pos = oldPos
-						break
-					}
-				}
+				player.Pos, _ = moveCircle(player.Pos, rl.Vector2Scale(moveDirection, dtSpeed), playerHalfWidth, blocks)
 
 				// Apply screen boundary constraints
 				screenWidth := float32(rl.GetScreenWidth())
@@ -491,21 +563,65 @@ func main() {
 				}
 			}
 
-			// Only call the parts of Update that don't involve movement
-			player.UpdateWithoutMovement(dt, currentTime)
+			// Slide back after being hit, heal once out of combat
+			player.UpdateKnockback(dt, blocks)
+			if !player.dying {
+				player.UpdateRegen(dt, currentTime)
 
-			// Update time alive only if game is not over and not paused
-			if !gameOver {
+				// Only call the parts of Update that don't involve movement
+				player.UpdateWithoutMovement(dt, currentTime)
+			}
+			player.UpdateAnimation(dt, moveDirection.X != 0 || moveDirection.Y != 0)
+
+			// Update time alive only while the player is alive and not paused
+			if !gameOver && !player.dying {
 				gameStats.timeAlive = currentTime - gameStartTime
+				gameStats.RecordWeaponTime(player.currentWeapon.weaponName, dt)
 			}
 
-			// Check if player is dead
+			// Check if player is dead, the game is over once the death clip has played.
+			// Time alive stopped counting at the moment of death
 			if player.CurrentHp <= 0 {
-				// Set game over flag to stop time counting
+				player.Kill()
+			}
+			if player.DeathFinished() {
+				// Set game over flag
 				if !gameOver {
 					gameOver = true
-					// Freeze time alive at the moment of death
-					gameStats.timeAlive = currentTime - gameStartTime
+					PlayStinger(StingerGameOver)
+					achievements.EndRun()
+
+					// Store the run, new records ask for a name first
+					lastRun = NewRunRecord(gameStats, scoring.score, player.currentWeapon.weaponName)
+					lastRunRank = -1
+					statsTab = TabSummary
+					exportMessage = ""
+					enteringName = runHistory.Qualifies(lastRun)
+					if !enteringName {
+						runHistory.Add(lastRun)
+					}
+				}
+
+				if enteringName && UpdateNameEntry(&playerName) {
+					enteringName = false
+					lastRun.Name = playerName
+					lastRunRank = runHistory.Add(lastRun)
+					statsTab = TabHighScores // Show where the new record landed
+				}
+
+				// Tabs and export only once the name is in
+				if !enteringName {
+					statsTab = UpdateStatsTab(statsTab)
+
+					if rl.IsKeyPressed(rl.KeyJ) {
+						path, err := ExportStats(&gameStats, scoring.score)
+						if err != nil {
+							rl.TraceLog(rl.LogWarning, "Failed to export stats: %s", err.Error())
+							exportMessage = "Export failed"
+						} else {
+							exportMessage = "Stats saved to " + path
+						}
+					}
 				}
 
 				rl.BeginDrawing()
@@ -514,57 +630,71 @@ func main() {
 				// Game over title
 				gameOverText := "GAME OVER"
 				textWidth := rl.MeasureText(gameOverText, 60)
-				rl.DrawText(gameOverText, int32(w)/2-textWidth/2, int32(h)/4, 60, rl.Red)
-
-				// Display statistics
-				statsY := int32(h)/4 + 100
-				statsSpacing := int32(35)
-
-				// Format time alive as minutes:seconds
-				minutes := int(gameStats.timeAlive) / 60
-				seconds := int(gameStats.timeAlive) % 60
-				timeAliveText := fmt.Sprintf("Time Survived: %d:%02d", minutes, seconds)
-
-				// Draw all stats
-				statsText := []string{
-					fmt.Sprintf("Level Reached: %d", gameStats.levelReached),
-					fmt.Sprintf("Enemies Killed: %d", gameStats.enemiesKilled),
-					fmt.Sprintf("Shots Fired: %d", gameStats.shotsFired),
-					fmt.Sprintf("Damage Dealt: %.0f", gameStats.damageDealt),
-					timeAliveText,
-					fmt.Sprintf("Grenades Thrown: %d", gameStats.grenadesThrown),
-				}
+				rl.DrawText(gameOverText, int32(w)/2-textWidth/2, int32(h)/8, 60, rl.Red)
+
+				// Name prompt for a new record, otherwise the tabbed statistics
+				contentY := int32(h)/8 + 160
+				if enteringName {
+					DrawNameEntry(playerName, int32(w)/2, int32(h)/2-60)
+				} else {
+					DrawStatsTabs(statsTab, int32(w)/2, int32(h)/8+90)
+
+					switch statsTab {
+					case TabSummary:
+						DrawStatsSummary(&gameStats, scoring.score, int32(w)/2, contentY)
+					case TabWeapons:
+						DrawWeaponStats(&gameStats, int32(w)/2, contentY)
+					case TabLevels:
+						DrawLevelStats(&gameStats, int32(w)/2, contentY)
+					case TabHighScores:
+						DrawHighScoreTable(runHistory.Top(), int32(w)/2, contentY, lastRunRank)
+					}
 
-				for i, text := range statsText {
-					textWidth := rl.MeasureText(text, 30)
-					rl.DrawText(text, int32(w)/2-textWidth/2, statsY+int32(i)*statsSpacing, 30, rl.Gold)
-				}
+					if exportMessage != "" {
+						exportWidth := rl.MeasureText(exportMessage, 20)
+						rl.DrawText(exportMessage, int32(w)/2-exportWidth/2, int32(h)-100, 20, rl.Gray)
+					}
 
-				// Restart prompt
-				restartText := "Press R to restart"
-				restartWidth := rl.MeasureText(restartText, 30)
-				rl.DrawText(restartText, int32(w)/2-restartWidth/2, int32(h)*3/4, 30, rl.White)
+					// Restart prompt
+					restartText := "TAB: switch view   J: export JSON   R: restart"
+					restartWidth := rl.MeasureText(restartText, 30)
+					rl.DrawText(restartText, int32(w)/2-restartWidth/2, int32(h)-60, 30, rl.White)
+				}
 
 				rl.EndDrawing()
 
-				if rl.IsKeyPressed(rl.KeyR) {
+				if !enteringName && rl.IsKeyPressed(rl.KeyR) {
 					// Reset the game
 					resetGameStats()
+					NewRunSeed()
+					achievements.StartRun()
 					gameStartTime = rl.GetTime() // Reset game time
 					gameOver = false             // Reset game over flag
+					scoring.Reset()
 
 					player = NewPlayer(1000)
 					enemyList = make([]*Enemy, 0)
 					projList = make([]*Projectile, 0)
 					grenadeList = make([]*Grenade, 0)
+					cookingGrenade = false
 					worldItems = make([]WorldItem, 0)
 					worldBodies = make([]Collides, 0)
 					loots = make([]*WeaponLoot, 0)
 					ammoLoots = make([]*AmmoLoot, 0)
+					coinLoots = make([]*CoinLoot, 0)
 					grenadePickups = make([]*GrenadePickup, 0)
-					bloodList = make([]*Blood, 0)
-					impacts = make([]*ImpactEffect, 0)
+					medkits = make([]*MedkitPickup, 0)
+					fireZones = make([]*FireZone, 0)
+					decals.Clear()
+					perkDraft.Clear()
+					shop.Close()
+					lootDropper.Reset()
+					lighting.Clear()
+					events.Clear()
+					notifications.Clear()
+					particles.Clear()
 					currentLevel = 1
+					lightingEnabled = isNightLevel(currentLevel)
 					enemiesRemaining = getEnemiesForLevel(currentLevel)
 					enemySpawnDelay = getEnemySpawnDelayForLevel(currentLevel) // Reset spawn delay
 					enemiesInPlay = 0
@@ -586,51 +716,64 @@ func main() {
 				continue
 			}
 
-			// Check if level is completed
-			if len(enemyList) == 0 && enemiesRemaining == 0 && !levelCompleted {
+			// Check if level is completed, not while the player is dying
+			if len(enemyList) == 0 && enemiesRemaining == 0 && !levelCompleted && !player.dying {
 				levelCompleted = true
 				levelCompletedTime = currentTime
+				Publish(events, LevelCompleted{Level: currentLevel, EnemyCount: getEnemiesForLevel(currentLevel), Time: currentTime})
 				currentLevel++
-				gameStats.levelReached = currentLevel // Update level reached in stats
 				enemiesRemaining = getEnemiesForLevel(currentLevel)
 				// Update spawn delay for the new level
 				enemySpawnDelay = getEnemySpawnDelayForLevel(currentLevel)
+
+				// Offer perks while the level complete message is up, then open the shop
+				perkDraft.Start(&player)
+				shop.Open(currentLevel)
+			}
+
+			// The key or click that picks a perk or buys an item mustn't also switch weapons or shoot,
+			// and a dying player can't do either. The draft may have just opened
+			menuOpen = perkDraft.Active() || shop.Active()
+			inputLocked := menuOpen || player.dying
+			if inputLocked {
+				holdFire = true
+			} else if !rl.IsMouseButtonDown(0) {
+				holdFire = false
 			}
 
 			// Handle level transition
 			if levelCompleted {
-				// Reset when transition time is over
-				if currentTime > levelCompletedTime+levelCompletedDuration {
+				if perkDraft.Active() {
+					perkDraft.Update(&player, int32(w), int32(h))
+				} else if shop.Active() {
+					// A weapon bought with a full inventory swaps out the one in hand
+					if dropped, magazine, ok := shop.Update(&player, int32(w), int32(h), currentTime); ok {
+						dropWeaponLoot(dropped, magazine, currentTime)
+					}
+				}
+
+				// Reset when transition time is over and the player is done with the perks and the shop
+				if currentTime > levelCompletedTime+levelCompletedDuration && !perkDraft.Active() && !shop.Active() {
 					levelCompleted = false
+					Publish(events, LevelStarted{Level: currentLevel, Time: currentTime})
+
+					// Night levels are played with the flashlight only
+					lightingEnabled = isNightLevel(currentLevel)
 
 					// Start fading out all blood when level ends
-					for i := range bloodList {
-						bloodList[i].fading = true
-					}
+					decals.FadeOut()
 				}
 			}
 
 			// Spawn weapon
-			{
-				if rl.GetRandomValue(0, 1000) < 1 {
-					x := rl.GetRandomValue(0, int32(w))
-					y := rl.GetRandomValue(0, int32(h))
+			if timedLootSpawns {
+				if RandomValue(0, 1000) < 1 {
+					pos, _ := spawner.Place(SpawnLoot, SpawnRule{Radius: pickupClearance(lootSize), MinPlayerDist: lootSpawnMinDist}, player.Pos)
 
 					// Pick a random weapon
-					weaponType := rl.GetRandomValue(0, 2)
-					var selectedWeapon weapon
-					switch weaponType {
-					case 0:
-						selectedWeapon = MITRA
-					case 1:
-						selectedWeapon = SHOTGUN
-					case 2:
-						selectedWeapon = MINIGUN
-					default:
-						selectedWeapon = PISTOL
-					}
+					selectedWeapon := lootWeapons[RandomValue(0, int32(len(lootWeapons)-1))]
 
-					loot := NewWeaponLoot(selectedWeapon, rl.NewVector2(float32(x), float32(y)), currentTime)
+					loot := NewWeaponLoot(selectedWeapon, rl.NewVector2(pos.X-lootSize/2, pos.Y-lootSize/2), currentTime)
 					worldBodies = append(worldBodies, loot)
 					worldItems = append(worldItems, loot)
 					loots = append(loots, loot)
@@ -638,18 +781,16 @@ func main() {
 			}
 
 			// Spawn ammo
-			{
+			if timedLootSpawns {
 				if currentTime > lastAmmoSpawn+ammoSpawnDelay {
 					lastAmmoSpawn = currentTime
 
-					if rl.GetRandomValue(0, 100) < 30 { // 30% chance to spawn ammo
-						x := rl.GetRandomValue(0, int32(w))
-						y := rl.GetRandomValue(0, int32(h))
+					if RandomValue(0, 100) < 30 { // 30% chance to spawn ammo
+						pos, _ := spawner.Place(SpawnLoot, SpawnRule{Radius: pickupClearance(lootSize), MinPlayerDist: lootSpawnMinDist}, player.Pos)
 
-						// Random ammo amount between 50-200
-						ammoAmount := rl.GetRandomValue(50, 200)
-
-						ammo := NewAmmoLoot(int(ammoAmount), rl.NewVector2(float32(x), float32(y)), currentTime)
+						// Mostly rounds for the guns the player carries
+						ammoType := RandomAmmoType(&player)
+						ammo := NewAmmoLoot(ammoType, ammoBoxAmount(ammoType), rl.NewVector2(pos.X-lootSize/2, pos.Y-lootSize/2), currentTime)
 						worldBodies = append(worldBodies, ammo)
 						worldItems = append(worldItems, ammo)
 						ammoLoots = append(ammoLoots, ammo)
@@ -668,31 +809,15 @@ func main() {
 				}
 
 				for _, l := range loots {
-					if rl.CheckCollisionCircleRec(player.Pos, playerSize*0.7, rl.NewRectangle(l.pos.X, l.pos.Y, lootSize, lootSize)) {
-						player.currentWeapon = l.weapon
-						player.isReloading = false // Cancel any reload in progress
-
-						// Initialize magazine for the new weapon
-						if player.currentWeapon.usesAmmo {
-							// If we have enough ammo, fill the magazine
-							if player.ammo >= player.currentWeapon.magazineSize {
-								player.currentMagazine = player.currentWeapon.magazineSize
-								player.ammo -= player.currentWeapon.magazineSize
-							} else {
-								// Otherwise use what we have
-								player.currentMagazine = player.ammo
-								player.ammo = 0
-							}
-						} else {
-							// Pistol always has full magazine
-							player.currentMagazine = player.currentWeapon.magazineSize
+					if currentTime >= l.pickupTime && !l.destroyed && rl.CheckCollisionCircleRec(player.Pos, playerSize*0.7, rl.NewRectangle(l.pos.X, l.pos.Y, lootSize, lootSize)) {
+						// A full inventory swaps out the weapon in hand
+						if dropped, magazine, ok := player.PickUpWeapon(l.weapon, l.magazine, currentTime); ok {
+							dropWeaponLoot(dropped, magazine, currentTime)
 						}
 
 						l.destroyed = true
-
-						// Store pickup message details
-						player.weaponPickupTime = currentTime
-						player.weaponPickupName = getWeaponName(l.weapon)
+						Publish(events, PickupCollected{Kind: "weapon", Amount: 1, Pos: player.Pos, Time: currentTime})
+						Publish(events, WeaponEquipped{Name: getWeaponName(l.weapon), Time: currentTime})
 					}
 				}
 			}
@@ -710,23 +835,62 @@ func main() {
 				// Handle ammo pickup
 				for _, a := range ammoLoots {
 					if !a.destroyed && rl.CheckCollisionCircleRec(player.Pos, playerSize*0.7, rl.NewRectangle(a.pos.X, a.pos.Y, lootSize, lootSize)) {
-						player.ammo += a.amount
-						a.destroyed = true
+						// Only what fits under the carry cap is taken, the rest stays in the box
+						taken := player.AddAmmo(a.ammoType, a.amount)
+						if taken == 0 {
+							continue
+						}
+						a.amount -= taken
+						a.destroyed = a.amount == 0
+						Publish(events, PickupCollected{Kind: "ammo", Name: ammoDefs[a.ammoType].name, Amount: taken, Pos: player.Pos, Time: currentTime})
+					}
+				}
+			}
 
-						// Store pickup message details
-						player.ammoPickupTime = currentTime
-						player.ammoPickupAmount = a.amount
+			// Collect coins
+			{
+				for _, c := range coinLoots {
+					if currentTime-c.createTime > coinLifetime {
+						c.destroyed = true
+					}
+					if !c.destroyed && rl.CheckCollisionCircles(player.Pos, playerSize*0.7, c.pos, coinRadius) {
+						player.coins += c.value
+						c.destroyed = true
+						Publish(events, PickupCollected{Kind: "coins", Amount: c.value, Pos: player.Pos, Time: currentTime})
+					}
+				}
+			}
+
+			// Switch and drop weapons, the number keys pick a perk or a shop item while they're up
+			if !inputLocked {
+				player.HandleWeaponInput(currentTime)
+
+				if rl.IsKeyPressed(rl.KeyG) {
+					if dropped, magazine, ok := player.DropWeapon(currentTime); ok {
+						dropWeaponLoot(dropped, magazine, currentTime)
 					}
 				}
 			}
 
 			// shoot
 			{
-				if rl.IsMouseButtonDown(0) {
-					if currentTime > player.currentWeapon.shootingDelay+float64(lastShoot) {
+				// Clicking a perk card or a shop item doesn't shoot
+				if rl.IsMouseButtonDown(0) && !holdFire {
+					if currentTime > player.Weapon().shootingDelay+float64(lastShoot) {
 						lastShoot = currentTime
 						shots := player.Shoot()
-						gameStats.shotsFired += len(shots) // Track shots fired
+						if len(shots) > 0 {
+							Publish(events, ShotFired{
+								Weapon: player.currentWeapon.weaponName,
+								Count:  len(shots),
+								Sound:  player.currentWeapon.shotSound,
+								Muzzle: rl.Vector2Add(player.Pos, rl.Vector2Scale(player.lookAt, playerSize*2)),
+								Dir:    player.lookAt,
+								Origin: player.Pos,
+							})
+						} else if !player.isReloading && !player.IsSwitching(currentTime) {
+							PlaySFXAt(SoundEmptyClick, player.Pos)
+						}
 						for _, p := range shots {
 							projList = append(projList, p)
 							worldItems = append(worldItems, p)
@@ -735,19 +899,40 @@ func main() {
 				}
 			}
 
-			// Place grenade when 'E' is pressed
+			// Hold 'E' to cook a grenade, release it to throw towards the cursor
 			{
-				if rl.IsKeyPressed(rl.KeyE) && currentTime > lastGrenade+grenadeDelay && player.grenades > 0 {
-					lastGrenade = currentTime
-					gameStats.grenadesThrown++ // Track grenades thrown
+				// Switch the kind of grenade, not while one is in hand
+				if !cookingGrenade && !player.dying && rl.IsKeyPressed(rl.KeyQ) {
+					player.CycleGrenade()
+				}
+
+				if !cookingGrenade && !player.dying && rl.IsKeyPressed(rl.KeyE) && currentTime > lastGrenade+grenadeDelay && player.Grenades() > 0 {
+					cookingGrenade = true
+					cookStart = currentTime
+				}
+
+				if cookingGrenade {
+					fuse := grenadeExplosionTime - (currentTime - cookStart)
+					if !rl.IsKeyDown(rl.KeyE) || fuse <= 0 {
+						target := rl.GetMousePosition()
 
-					// Create new grenade at player position
-					grenade := NewGrenade(player.Pos, currentTime)
-					grenadeList = append(grenadeList, grenade)
-					worldItems = append(worldItems, grenade)
+						// Cooked for too long, it goes off in hand
+						if fuse <= 0 {
+							fuse = 0
+							target = player.Pos
+						}
+
+						cookingGrenade = false
+						lastGrenade = currentTime
+						Publish(events, GrenadeThrown{Pos: player.Pos})
 
-					// Decrease player's grenade count
-					player.grenades--
+						// Decrease player's grenade count
+						kind := player.UseGrenade()
+
+						grenade := NewGrenade(kind, player.GrenadeRadius(kind), player.Pos, target, fuse, currentTime)
+						grenadeList = append(grenadeList, grenade)
+						worldItems = append(worldItems, grenade)
+					}
 				}
 			}
 
@@ -756,46 +941,28 @@ func main() {
 				if !levelCompleted && enemiesRemaining > 0 && enemiesInPlay < maxConcurrentEnemies && currentTime > lastEnemySpawn+enemySpawnDelay {
 					lastEnemySpawn = currentTime
 
-					respawn := true
-					enemyHealth := getEnemyHealthForLevel(currentLevel)
-					enemyDamage := getEnemyDamageForLevel(currentLevel)
-					n := NewEnemy(rl.NewVector2(100, 110), enemyHealth, enemyDamage, enemySize)
-
-					for respawn {
-						respawn = false
-						spawnPosition := RandomPointInCircle(200)
-						spawnPosition = rl.Vector2Add(spawnPosition, player.Pos)
-
-						n = NewEnemy(rl.NewVector2(float32(spawnPosition.X), float32(spawnPosition.Y)), enemyHealth, enemyDamage, enemySize)
-
-						// Check if enemy is inside a block
-						enemyRect := rl.NewRectangle(n.pos.X-enemySize, n.pos.Y-enemySize, enemySize*2, enemySize*2)
-						for _, block := range blocks {
-							if rl.CheckCollisionRecs(enemyRect, block.GetRectangle()) {
-								respawn = true
-								break
+					// Away from blocks and the player, and not on top of another zombie
+					pos, _ := spawner.Place(SpawnEnemies, SpawnRule{
+						Radius:        enemySize,
+						MinPlayerDist: enemySpawnMinDist,
+						MaxPlayerDist: enemySpawnMaxDist,
+						Occupied: func(pos rl.Vector2) bool {
+							for _, e := range enemyList {
+								if rl.CheckCollisionCircles(pos, enemySize, e.pos, enemySize) {
+									return true
+								}
 							}
-						}
-
-						if n.pos.X < 100 || n.pos.Y < 100 {
-							respawn = true
-							continue
-						}
-						for _, e := range enemyList {
-							if rl.CheckCollisionCircles(n.pos, enemySize, e.pos, enemySize) {
-								respawn = true
-								break
-							}
-						}
-
-						if !respawn {
-							enemyList = append(enemyList, n)
-							worldItems = append(worldItems, n)
-							worldBodies = append(worldBodies, n)
-							enemiesRemaining--
-							enemiesInPlay++
-						}
-					}
+							return false
+						},
+					}, player.Pos)
+
+					n := NewEnemy(pos, getEnemyHealthForLevel(currentLevel), getEnemyDamageForLevel(currentLevel), enemySize)
+					n.level = currentLevel
+					enemyList = append(enemyList, n)
+					worldItems = append(worldItems, n)
+					worldBodies = append(worldBodies, n)
+					enemiesRemaining--
+					enemiesInPlay++
 				}
 			}
 
@@ -806,20 +973,36 @@ func main() {
 
 			// Update grenades
 			for _, g := range grenadeList {
-				g.Update(currentTime, enemyList)
+				g.Update(dt, currentTime, enemyList, blocks, player.Pos)
+
+				// Caught in the blast of your own grenade
+				if g.selfDamage > 0 && player.Hit(g.selfDamage, g.pos, currentTime) {
+					Publish(events, DamageTaken{Amount: g.selfDamage, Level: currentLevel, Pos: player.Pos})
+				}
+				g.selfDamage = 0
+
+				// Frag and incendiary grenades leave shrapnel and fire behind
+				for _, p := range g.shrapnel {
+					projList = append(projList, p)
+					worldItems = append(worldItems, p)
+				}
+				g.shrapnel = nil
+				if g.fire != nil {
+					fireZones = append(fireZones, g.fire)
+					worldItems = append(worldItems, g.fire)
+					g.fire = nil
+				}
+			}
+
+			// Burn the enemies standing in fire
+			for _, f := range fireZones {
+				f.Update(currentTime, enemyList)
 			}
 
 			// Check for enemies killed by grenades
 			for i := len(enemyList) - 1; i >= 0; i-- {
-				if enemyList[i].health <= 0 && !enemyList[i].destroyed {
-					enemyList[i].destroyed = true
-					enemiesInPlay--
-					gameStats.enemiesKilled++ // Count enemy killed
-
-					// Add blood at enemy position when it dies from projectile hit
-					blood := NewBlood(enemyList[i].pos)
-					worldItems = append(worldItems, blood)
-					bloodList = append(bloodList, blood)
+				if enemyList[i].health <= 0 && !enemyList[i].dying {
+					killEnemy(enemyList[i], GRENADE_WEAPON_NAME, currentTime)
 				}
 			}
 
@@ -828,16 +1011,29 @@ func main() {
 				p.Move(player.Pos, dt)
 			}
 
+			// Animate enemies and turn the ones done dying into blood
+			for _, e := range enemyList {
+				e.UpdateAnimation(dt)
+
+				if e.DeathFinished() && !e.destroyed {
+					e.destroyed = true
+
+					// Add blood where the body fell
+					decals.StampBlood(e.pos)
+				}
+			}
+
 			// Spawn grenade pickups
-			{
+			if timedLootSpawns {
 				if currentTime > lastGrenadePickupSpawn+grenadePickupDelay {
 					lastGrenadePickupSpawn = currentTime
 
-					if rl.GetRandomValue(0, 100) < 40 { // 40% chance to spawn grenade pickup
-						x := rl.GetRandomValue(0, int32(w))
-						y := rl.GetRandomValue(0, int32(h))
-
-						pickup := NewGrenadePickup(rl.NewVector2(float32(x), float32(y)), currentTime)
+					if RandomValue(0, 100) < 40 { // 40% chance to spawn grenade pickup
+						kind := RandomGrenadeKind()
+						pickup := NewGrenadePickup(kind, grenadeDefs[kind].lootAmount, rl.Vector2Zero(), currentTime)
+						size := float32(pickup.size)
+						pos, _ := spawner.Place(SpawnLoot, SpawnRule{Radius: pickupClearance(size), MinPlayerDist: lootSpawnMinDist}, player.Pos)
+						pickup.pos = rl.NewVector2(pos.X-size/2, pos.Y-size/2)
 						worldItems = append(worldItems, pickup)
 						grenadePickups = append(grenadePickups, pickup)
 					}
@@ -857,8 +1053,42 @@ func main() {
 				for _, g := range grenadePickups {
 					if !g.destroyed && rl.CheckCollisionCircleRec(player.Pos, playerSize*0.7,
 						rl.NewRectangle(g.pos.X, g.pos.Y, float32(g.size), float32(g.size))) {
-						player.grenades += g.amount
+						player.AddGrenades(g.kind, g.amount)
 						g.destroyed = true
+						Publish(events, PickupCollected{Kind: "grenade", Name: grenadeDefs[g.kind].name, Amount: g.amount, Pos: player.Pos, Time: currentTime})
+					}
+				}
+			}
+
+			// Spawn medkits
+			if timedLootSpawns {
+				if currentTime > lastMedkitSpawn+medkitSpawnDelay {
+					lastMedkitSpawn = currentTime
+
+					if RandomValue(0, 100) < 35 { // 35% chance to spawn a medkit
+						size := float32(medkitSize)
+						pos, _ := spawner.Place(SpawnLoot, SpawnRule{Radius: pickupClearance(size), MinPlayerDist: lootSpawnMinDist}, player.Pos)
+
+						medkit := NewMedkitPickup(rl.NewVector2(pos.X-size/2, pos.Y-size/2), currentTime)
+						worldItems = append(worldItems, medkit)
+						medkits = append(medkits, medkit)
+					}
+				}
+			}
+
+			// Handle medkit timeout and pickup
+			{
+				for _, m := range medkits {
+					if currentTime-m.createTime > medkitLifetime {
+						m.destroyed = true
+					}
+
+					// Left on the floor while the player is at full health
+					if !m.destroyed && player.CurrentHp < player.MaxHp() && rl.CheckCollisionCircleRec(player.Pos, playerSize*0.7,
+						rl.NewRectangle(m.pos.X, m.pos.Y, float32(medkitSize), float32(medkitSize))) {
+						healed := player.Heal(m.amount)
+						m.destroyed = true
+						Publish(events, PickupCollected{Kind: "medkit", Amount: healed, Pos: player.Pos, Time: currentTime})
 					}
 				}
 			}
@@ -886,10 +1116,9 @@ func main() {
 				// Check collision with each block
 				for _, block := range blocks {
 					if rl.CheckCollisionRecs(projRect, block.GetRectangle()) {
-						// Create impact effect
-						impact := NewImpactEffect(proj.pos, rl.Yellow)
-						impacts = append(impacts, impact)
-						worldItems = append(worldItems, impact)
+						// Sparks fly back towards the shooter
+						particles.Emit(EmitterBulletImpact, proj.pos, rl.Vector2Negate(proj.dir))
+						decals.StampBulletHole(proj.pos)
 
 						// Projectile hit a block, destroy it
 						proj.destroyed = true
@@ -901,42 +1130,44 @@ func main() {
 			// check collision between proj and enemy
 			for _, p := range projList {
 				for _, e := range enemyList {
-					if rl.CheckCollisionCircles(p.pos, projSize, e.pos, enemySize) {
+					// Bullets fly over bodies that are already dying
+					if e.dying {
+						continue
+					}
+					if rl.CheckCollisionCircles(p.pos, projSize, e.pos, enemySize) && !p.HasHit(e) {
 						e.DealDamage(p.damage)
-						gameStats.damageDealt += p.damage // Track damage dealt
+						// One bullet going through a crowd is still one hit
+						Publish(events, EnemyHit{Enemy: e, Weapon: p.weaponName, Damage: p.damage, Dir: p.dir, Hit: !p.destroyed && len(p.hits) == 0 && !p.shrapnel})
+						if !p.shrapnel {
+							player.Lifesteal(p.damage)
+						}
 						if e.health <= 0 {
-							e.destroyed = true
-							enemiesInPlay--
-							gameStats.enemiesKilled++ // Count enemy killed
-
-							// Add blood at enemy position when it dies from projectile hit
-							blood := NewBlood(e.pos)
-							worldItems = append(worldItems, blood)
-							bloodList = append(bloodList, blood)
+							killEnemy(e, p.weaponName, currentTime)
+						}
+
+						// Piercing rounds keep going until they've gone through enough enemies
+						p.hits = append(p.hits, e)
+						if len(p.hits) > p.pierce {
+							p.destroyed = true
 						}
-						p.destroyed = true
 					}
 				}
 			}
 
-			// Check collision between enemies and player
+			// Zombies next to the player wind up and attack, a hit makes the player invulnerable for a moment
 			for _, e := range enemyList {
-				if rl.CheckCollisionCircles(player.Pos, playerSize*0.7, e.pos, enemySize) {
-					// Apply damage to player based on enemy's damage stat
-					player.TakeDamage(e.damage)
-
-					// Simple invulnerability frame mechanic by slightly pushing enemy away
-					dir := rl.Vector2Subtract(e.pos, player.Pos)
-					if dir.X == 0 && dir.Y == 0 {
-						dir = rl.NewVector2(float32(rl.GetRandomValue(-10, 10))*0.1,
-							float32(rl.GetRandomValue(-10, 10))*0.1)
-					}
-					dir = rl.Vector2Normalize(dir)
-					pushDistance := float32(10.0) // Slight push
-					pushVector := rl.Vector2Scale(dir, pushDistance)
-					e.pos = rl.Vector2Add(e.pos, pushVector)
+				if e.UpdateAttack(player.Pos, playerSize*0.7, dt) && player.Hit(e.damage, e.pos, currentTime) {
+					Publish(events, DamageTaken{Amount: e.damage, Level: currentLevel, Pos: player.Pos})
 				}
 			}
+
+			// Hand this frame's events to stats, score, audio, effects and UI
+			events.Dispatch()
+		}
+
+		// The light map has to be drawn before the frame starts
+		if lightingEnabled {
+			lighting.Build(&player, enemyList, blocks)
 		}
 
 		// Always render, even when paused
@@ -968,32 +1199,46 @@ func main() {
 				rl.ClearBackground(rl.DarkGray)
 			}
 
-			// Draw blood first (so it's underneath everything else)
-			for _, blood := range bloodList {
-				blood.Render()
+			// Queue everything in the world, each item goes to its own layer
+			renderQueue.Reset()
+			renderQueue.Add(decals)
+			for _, item := range worldItems {
+				renderQueue.Add(item)
+			}
+			renderQueue.Add(&player)
+			renderQueue.Add(particles)
+			renderQueue.Add(&scoring)
+			renderQueue.Add(achievements)
+			if lightingEnabled {
+				renderQueue.Add(lighting)
 			}
 
-			// Then draw other world items (but skip blood which we already drew)
-			for _, item := range worldItems {
-				if _, isBlood := item.(*Blood); !isBlood {
-					item.Render()
-				}
+			renderQueue.Draw(LayerGround, LayerHUD)
+
+			// Red flash and the direction of the last hit
+			player.RenderHitFeedback(int32(w), int32(h), currentTime)
+
+			// Where the grenade in hand would land
+			if cookingGrenade {
+				fuse := grenadeExplosionTime - (currentTime - cookStart)
+				grenadePreview = PredictGrenadePath(player.Pos, rl.GetMousePosition(), fuse, blocks, grenadePreview[:0])
+				DrawGrenadePreview(grenadePreview, fuse, player.GrenadeRadius(player.grenadeKind))
 			}
 
-			player.Render()
 			rl.DrawFPS(10, 10)
 
-			// Draw player HP in the top-right corner of the screen
-			healthText := fmt.Sprintf("HP: %d/%d", player.CurrentHp, player.TotalHp)
-			textWidth := rl.MeasureText(healthText, 20)
-			rl.DrawText(healthText, int32(w)-textWidth-20, 20, 20, rl.White)
+			// Score and combo multiplier
+			scoring.RenderHUD(int32(w), currentTime)
+
+			// Draw player HP and armor bars in the top-right corner of the screen
+			player.RenderHealthBars(int32(w)-20, 14, currentTime)
 
 			// Draw ammo count
 			var ammoText string
 			if player.currentWeapon.usesAmmo {
-				ammoText = fmt.Sprintf("Ammo: %d / %d", player.currentMagazine, player.ammo)
+				ammoText = fmt.Sprintf("Ammo: %d / %d %s", player.currentMagazine, player.Ammo(), ammoDefs[player.currentWeapon.ammoType].name)
 			} else {
-				ammoText = fmt.Sprintf("Ammo: ∞ / %d", player.ammo) // Infinite for pistol
+				ammoText = fmt.Sprintf("Ammo: %d / ∞", player.currentMagazine) // Infinite for pistol
 			}
 			ammoWidth := rl.MeasureText(ammoText, 20)
 			rl.DrawText(ammoText, int32(w)-ammoWidth-20, 50, 20, rl.White)
@@ -1006,7 +1251,7 @@ func main() {
 			// Show reload key hint if magazine not full
 			if player.currentMagazine < player.currentWeapon.magazineSize &&
 				!player.isReloading &&
-				(player.ammo > 0 || !player.currentWeapon.usesAmmo) {
+				(player.Ammo() > 0 || !player.currentWeapon.usesAmmo) {
 				reloadText := "Press R to reload"
 				reloadWidth := rl.MeasureText(reloadText, 18)
 				rl.DrawText(reloadText, int32(w)-reloadWidth-20, 110, 18, rl.Gray)
@@ -1014,7 +1259,7 @@ func main() {
 
 			// Show reload state if reloading
 			if player.isReloading {
-				reloadProgress := (currentTime - player.reloadStartTime) / player.currentWeapon.reloadTime * 100
+				reloadProgress := (currentTime - player.reloadStartTime) / player.Weapon().reloadTime * 100
 				reloadText := fmt.Sprintf("RELOADING... %.0f%%", reloadProgress)
 				reloadWidth := rl.MeasureText(reloadText, 25)
 				rl.DrawText(reloadText, int32(w)/2-reloadWidth/2, int32(h)-120, 25, rl.Yellow)
@@ -1027,6 +1272,15 @@ func main() {
 			enemiesText := fmt.Sprintf("Enemies remaining: %d", enemiesRemaining+len(enemyList))
 			rl.DrawText(enemiesText, 10, 70, 20, rl.White)
 
+			// Carried weapons, the one in hand highlighted
+			player.RenderInventory(14, 110, currentTime)
+
+			// Coins to spend in the shop
+			rl.DrawText(fmt.Sprintf("Coins: %d", player.coins), 14, 110+INVENTORY_SLOTS*28+10, 20, rl.Gold)
+
+			// Perks taken this run
+			player.RenderPerks(int32(w)-20, int32(h)-60)
+
 			// Show level complete message
 			if levelCompleted {
 				levelCompleteText := fmt.Sprintf("LEVEL %d COMPLETE!", currentLevel-1)
@@ -1036,31 +1290,31 @@ func main() {
 				nextLevelText := fmt.Sprintf("NEXT LEVEL: %d", currentLevel)
 				nextLevelWidth := rl.MeasureText(nextLevelText, 30)
 				rl.DrawText(nextLevelText, int32(w)/2-nextLevelWidth/2, int32(h)/2+30, 30, rl.Green)
-			}
 
-			// Show weapon pickup message for 2 seconds
-			if player.weaponPickupName != "" && currentTime-player.weaponPickupTime < 2.0 {
-				pickupText := fmt.Sprintf("Acquired: %s", player.weaponPickupName)
-				textWidth := rl.MeasureText(pickupText, 30)
-				rl.DrawText(pickupText, int32(w)/2-textWidth/2, int32(h)-50, 30, rl.Yellow)
-			}
+				// Warn the player the lights are going out
+				if isNightLevel(currentLevel) {
+					nightText := "NIGHT FALLS... STAY IN THE LIGHT"
+					nightWidth := rl.MeasureText(nightText, 25)
+					rl.DrawText(nightText, int32(w)/2-nightWidth/2, int32(h)/2+70, 25, rl.Orange)
+				}
 
-			// Show ammo pickup message for 2 seconds
-			if player.ammoPickupAmount > 0 && currentTime-player.ammoPickupTime < 2.0 {
-				ammoText := fmt.Sprintf("Ammo +%d", player.ammoPickupAmount)
-				textWidth := rl.MeasureText(ammoText, 30)
-				rl.DrawText(ammoText, int32(w)/2-textWidth/2, int32(h)-90, 30, rl.Yellow)
+				if perkDraft.Active() {
+					perkDraft.Render(&player, int32(w), int32(h))
+				} else if shop.Active() {
+					shop.Render(&player, int32(w), int32(h))
+				}
 			}
 
+			// Weapon and ammo pickup messages
+			notifications.Render(int32(w), int32(h), currentTime)
+
 			// Show grenade key hint
-			grenadeText := "Press E to place grenade"
+			grenadeText := "Hold E to cook, release to throw, Q to switch"
 			grenadeWidth := rl.MeasureText(grenadeText, 18)
 			rl.DrawText(grenadeText, int32(w)-grenadeWidth-20, 140, 18, rl.Gray)
 
-			// Show grenade count
-			grenadeCountText := fmt.Sprintf("Grenades: %d", player.grenades)
-			grenadeCountWidth := rl.MeasureText(grenadeCountText, 20)
-			rl.DrawText(grenadeCountText, int32(w)-grenadeCountWidth-20, 170, 20, rl.White)
+			// Show grenade count of every kind
+			player.RenderGrenades(int32(w)-20, 170)
 
 			if showGrid {
 				spaceGrid.Draw();
@@ -1080,6 +1334,10 @@ func main() {
 				controlsText := "Press ESC to resume"
 				controlsWidth := rl.MeasureText(controlsText, 30)
 				rl.DrawText(controlsText, int32(w)/2-controlsWidth/2, int32(h)/2+20, 30, rl.White)
+
+				// The volume can be changed without leaving the run
+				UpdateVolumeSettings(&volumeRow)
+				DrawVolumeSettings(volumeRow, int32(w)/2, int32(h)/2+80)
 			}
 		}
 		rl.EndDrawing()
@@ -1093,30 +1351,43 @@ func main() {
 			ammoLoots = UpdateWorldItems(ammoLoots)
 			grenadeList = UpdateWorldItems(grenadeList)
 			grenadePickups = UpdateWorldItems(grenadePickups)
+			medkits = UpdateWorldItems(medkits)
+			fireZones = UpdateWorldItems(fireZones)
+			coinLoots = UpdateWorldItems(coinLoots)
 
-			// Update blood (for fading effect)
-			for _, blood := range bloodList {
-				blood.Update(dt)
-			}
-			bloodList = UpdateWorldItems(bloodList)
+			// Bake new decals (and fade them when the level ends)
+			decals.Update(dt)
 
-			// Update impacts
-			for _, impact := range impacts {
-				impact.Update(dt)
-			}
-			impacts = UpdateWorldItems(impacts)
+			// Update particles
+			particles.Update(dt)
+
+			// Fade gunshot and explosion lights
+			lighting.Update(dt)
+
+			// Decay the combo multiplier and move score pop-ups
+			scoring.Update(dt, currentTime)
+
+			// Time based achievements and unlock toasts, the clock only runs during the fight
+			achievements.RecordBest(StatScore, float64(scoring.score))
+			achievements.Update(dt, !levelCompleted && !gameOver && !player.dying)
 		}
 
 		lastTime = currentTime
 	}
 
 	// Unload textures before closing
-	rl.UnloadTexture(player.spriteLeft)
-	rl.UnloadTexture(player.spriteRight)
+	UnloadPlayerSprites()
 	rl.UnloadTexture(backgroundTexture)
 	rl.UnloadTexture(bloodTexture)
+	decals.Unload()
+	lighting.Unload()
 	UnloadEnemySprite()
 	UnloadBulletSprite()
+	UnloadMusic()
+	UnloadAudio()
+
+	// Keep the lifetime counters of a run that was quit halfway
+	achievements.Save()
 
 	rl.CloseWindow()
 }
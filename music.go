@@ -0,0 +1,213 @@
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// MusicIntensity is the mood the soundtrack fades towards
+type MusicIntensity int
+
+const (
+	IntensityCalm MusicIntensity = iota
+	IntensityCombat
+	IntensityBoss
+	IntensitySilence // Every layer fades out (used on game over)
+)
+
+// Number of streamed layers, one per audible intensity
+const musicLayerCount = int(IntensitySilence)
+
+// Stinger identifies a short one-shot music cue
+type Stinger int
+
+const (
+	StingerLevelComplete Stinger = iota
+	StingerGameOver
+	stingerCount
+)
+
+var musicLayerPaths = [musicLayerCount]string{
+	IntensityCalm:   "assets/music/calm.ogg",
+	IntensityCombat: "assets/music/combat.ogg",
+	IntensityBoss:   "assets/music/boss.ogg",
+}
+
+var stingerPaths = [stingerCount]string{
+	StingerLevelComplete: "assets/music/level_complete.ogg",
+	StingerGameOver:      "assets/music/game_over.ogg",
+}
+
+// Volume of every layer for each intensity, the layers are stacked on top of each other
+var musicLayerMix = [IntensitySilence + 1][musicLayerCount]float32{
+	IntensityCalm:    {1.0, 0.0, 0.0},
+	IntensityCombat:  {0.6, 1.0, 0.0},
+	IntensityBoss:    {0.4, 1.0, 1.0},
+	IntensitySilence: {0.0, 0.0, 0.0},
+}
+
+var (
+	musicVolume      float32 = 0.6
+	musicFadeSpeed   float32 = 0.5 // Volume change per second while crossfading
+	musicPauseVolume float32 = 0.3 // Music is ducked to this factor while paused
+	stingerDuck      float32 = 0.2 // Layers are ducked to this factor while a stinger plays
+	stingerDuration  float64 = 2.5 // How long the layers stay ducked after a stinger
+)
+
+// musicLayer is one looping stream of the soundtrack
+type musicLayer struct {
+	stream rl.Music
+	loaded bool
+	volume float32 // Current volume before master music volume is applied
+}
+
+// MusicPlayer crossfades layered music streams based on the game intensity
+type MusicPlayer struct {
+	layers      [musicLayerCount]musicLayer
+	stingers    [stingerCount]rl.Sound
+	stingerTime float64 // When the last stinger started
+	intensity   MusicIntensity
+}
+
+var music MusicPlayer
+
+// Load the music layers and stingers and start streaming them
+func InitMusic() {
+	music = MusicPlayer{intensity: IntensityCalm, stingerTime: -stingerDuration}
+
+	if !audioReady {
+		return
+	}
+
+	rl.TraceLog(rl.LogWarning, "Loading music layers...")
+
+	for i, path := range musicLayerPaths {
+		stream := rl.LoadMusicStream(path)
+		if stream.FrameCount == 0 {
+			rl.TraceLog(rl.LogWarning, "Failed to load music layer %s! It will be silent.", path)
+			continue
+		}
+
+		// All layers play in sync from the start, only their volume changes
+		stream.Looping = true
+		rl.SetMusicVolume(stream, 0)
+		rl.PlayMusicStream(stream)
+
+		music.layers[i] = musicLayer{stream: stream, loaded: true}
+	}
+
+	for i, path := range stingerPaths {
+		music.stingers[i] = rl.LoadSound(path)
+		if music.stingers[i].FrameCount == 0 {
+			rl.TraceLog(rl.LogWarning, "Failed to load music stinger %s! It will be silent.", path)
+		}
+	}
+}
+
+// Unload the music layers and stingers
+func UnloadMusic() {
+	if !audioReady {
+		return
+	}
+
+	for i := range music.layers {
+		if music.layers[i].loaded {
+			rl.StopMusicStream(music.layers[i].stream)
+			rl.UnloadMusicStream(music.layers[i].stream)
+			music.layers[i].loaded = false
+		}
+	}
+
+	for _, stinger := range music.stingers {
+		if stinger.FrameCount > 0 {
+			rl.UnloadSound(stinger)
+		}
+	}
+}
+
+// Pick the intensity the soundtrack should play from the live game state
+func musicIntensityFor(enemiesInPlay int, hpRatio float32, levelCompleted bool) MusicIntensity {
+	if levelCompleted || enemiesInPlay == 0 {
+		return IntensityCalm
+	}
+
+	// Big hordes or being close to death feel like a boss fight
+	if enemiesInPlay >= 25 || hpRatio < 0.3 {
+		return IntensityBoss
+	}
+
+	if enemiesInPlay >= 3 {
+		return IntensityCombat
+	}
+
+	return IntensityCalm
+}
+
+// Set the volume of the music layers and stingers (0-1), the layers pick it up on the next update
+func SetMusicVolume(volume float32) {
+	musicVolume = rl.Clamp(volume, 0, 1)
+}
+
+// Set the intensity the layers crossfade towards
+func SetMusicIntensity(intensity MusicIntensity) {
+	music.intensity = intensity
+}
+
+// Play a one-shot stinger and duck the layers while it plays
+func PlayStinger(stinger Stinger) {
+	if !audioReady {
+		return
+	}
+
+	music.stingerTime = rl.GetTime()
+
+	sound := music.stingers[stinger]
+	if sound.FrameCount == 0 {
+		return
+	}
+	rl.SetSoundVolume(sound, musicVolume)
+	rl.PlaySound(sound)
+}
+
+// Keep the streams fed and move the layer volumes towards the current intensity
+func UpdateMusic(dt float64, paused bool) {
+	if !audioReady {
+		return
+	}
+
+	duck := float32(1.0)
+	if paused {
+		duck = musicPauseVolume
+	}
+	if rl.GetTime()-music.stingerTime < stingerDuration {
+		duck *= stingerDuck
+	}
+
+	step := musicFadeSpeed * float32(dt)
+	mix := musicLayerMix[music.intensity]
+
+	for i := range music.layers {
+		layer := &music.layers[i]
+		if !layer.loaded {
+			continue
+		}
+
+		rl.UpdateMusicStream(layer.stream)
+
+		// Move the volume towards the target without overshooting
+		target := mix[i]
+		if layer.volume < target {
+			layer.volume = rl.Clamp(layer.volume+step, 0, target)
+		} else if layer.volume > target {
+			layer.volume = rl.Clamp(layer.volume-step, target, 1)
+		}
+
+		rl.SetMusicVolume(layer.stream, layer.volume*musicVolume*duck)
+	}
+}
+
+// Play the stingers of gameplay events
+func subscribeMusic(bus *EventBus) {
+	Subscribe(bus, func(e LevelCompleted) {
+		PlayStinger(StingerLevelComplete)
+	})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// How long pickup messages stay on screen
+var notificationDuration float64 = 2.0
+
+// Notifications shows pickup messages at the bottom of the screen
+type Notifications struct {
+	// Weapon pickup notification
+	weaponTime float64
+	weaponName string
+
+	// Ammo pickup notification
+	ammoTime   float64
+	ammoAmount int
+	ammoName   string
+}
+
+// Shared pickup notifications
+var notifications Notifications
+
+// Show pickup messages when weapons and ammo are collected
+func subscribeNotifications(bus *EventBus) {
+	Subscribe(bus, func(e WeaponEquipped) {
+		notifications.weaponTime = e.Time
+		notifications.weaponName = e.Name
+	})
+	Subscribe(bus, func(e PickupCollected) {
+		if e.Kind == "ammo" {
+			notifications.ammoTime = e.Time
+			notifications.ammoAmount = e.Amount
+			notifications.ammoName = e.Name
+		}
+	})
+}
+
+// Forget the messages of the last run
+func (n *Notifications) Clear() {
+	*n = Notifications{}
+}
+
+// Draw the messages that haven't expired yet
+func (n *Notifications) Render(screenWidth, screenHeight int32, currentTime float64) {
+	// Show weapon pickup message
+	if n.weaponName != "" && currentTime-n.weaponTime < notificationDuration {
+		pickupText := fmt.Sprintf("Acquired: %s", n.weaponName)
+		textWidth := rl.MeasureText(pickupText, 30)
+		rl.DrawText(pickupText, screenWidth/2-textWidth/2, screenHeight-50, 30, rl.Yellow)
+	}
+
+	// Show ammo pickup message
+	if n.ammoAmount > 0 && currentTime-n.ammoTime < notificationDuration {
+		ammoText := fmt.Sprintf("%s ammo +%d", n.ammoName, n.ammoAmount)
+		textWidth := rl.MeasureText(ammoText, 30)
+		rl.DrawText(ammoText, screenWidth/2-textWidth/2, screenHeight-90, 30, rl.Yellow)
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Maximum number of particles alive at the same time
+const MAX_PARTICLES = 4096
+
+const EMITTERS_FILE = "assets/emitters.json"
+
+// ParticleShape is how a particle is drawn
+type ParticleShape int
+
+const (
+	ShapeCircle ParticleShape = iota
+	ShapeRect
+)
+
+// EmitterID identifies one of the emitter definitions
+type EmitterID int
+
+const (
+	EmitterMuzzleFlash EmitterID = iota
+	EmitterShellCasing
+	EmitterBulletImpact
+	EmitterBloodSpray
+	EmitterExplosionFire
+	EmitterExplosionDebris
+	EmitterSmoke
+	EmitterFlames
+	EmitterFrost
+	emitterCount
+)
+
+// EmitterDef describes a burst of particles
+type EmitterDef struct {
+	countMin, countMax int     // Particles per burst
+	spread             float32 // Cone around the emit direction in degrees, 360 emits all around
+	speedMin, speedMax float32 // Initial speed in pixels per second
+	lifeMin, lifeMax   float32 // Lifetime in seconds
+	sizeStart, sizeEnd float32 // Size at birth and at death
+	colorStart         rl.Color
+	colorEnd           rl.Color
+	drag               float32 // Fraction of velocity lost per second
+	gravity            float32 // Downward acceleration in pixels per second squared
+	spin               float32 // Maximum rotation speed in degrees per second
+	shape              ParticleShape
+}
+
+// Names of the emitters in the data file
+var emitterNames = [emitterCount]string{
+	EmitterMuzzleFlash:     "muzzleFlash",
+	EmitterShellCasing:     "shellCasing",
+	EmitterBulletImpact:    "bulletImpact",
+	EmitterBloodSpray:      "bloodSpray",
+	EmitterExplosionFire:   "explosionFire",
+	EmitterExplosionDebris: "explosionDebris",
+	EmitterSmoke:           "smoke",
+	EmitterFlames:          "flames",
+	EmitterFrost:           "frost",
+}
+
+// Emitters loaded from the data file
+var emitterDefs [emitterCount]EmitterDef
+
+// Plain white puff used for any emitter the data file doesn't define
+var fallbackEmitter = EmitterDef{
+	countMin: 3, countMax: 5, spread: 360,
+	speedMin: 50, speedMax: 150, lifeMin: 0.2, lifeMax: 0.4,
+	sizeStart: 3, sizeEnd: 1,
+	colorStart: rl.White, colorEnd: rl.NewColor(255, 255, 255, 0),
+	drag: 4,
+}
+
+// EmitterData is one emitter as written in the data file, colors are [r, g, b, a]
+type EmitterData struct {
+	CountMin   int      `json:"countMin"`
+	CountMax   int      `json:"countMax"`
+	Spread     float32  `json:"spread"`
+	SpeedMin   float32  `json:"speedMin"`
+	SpeedMax   float32  `json:"speedMax"`
+	LifeMin    float32  `json:"lifeMin"`
+	LifeMax    float32  `json:"lifeMax"`
+	SizeStart  float32  `json:"sizeStart"`
+	SizeEnd    float32  `json:"sizeEnd"`
+	ColorStart [4]uint8 `json:"colorStart"`
+	ColorEnd   [4]uint8 `json:"colorEnd"`
+	Drag       float32  `json:"drag"`
+	Gravity    float32  `json:"gravity"`
+	Spin       float32  `json:"spin"`
+	Shape      string   `json:"shape"` // "circle" or "rect"
+}
+
+func (data *EmitterData) def() EmitterDef {
+	shape := ShapeCircle
+	if data.Shape == "rect" {
+		shape = ShapeRect
+	}
+	return EmitterDef{
+		countMin:   data.CountMin,
+		countMax:   data.CountMax,
+		spread:     data.Spread,
+		speedMin:   data.SpeedMin,
+		speedMax:   data.SpeedMax,
+		lifeMin:    data.LifeMin,
+		lifeMax:    data.LifeMax,
+		sizeStart:  data.SizeStart,
+		sizeEnd:    data.SizeEnd,
+		colorStart: rl.NewColor(data.ColorStart[0], data.ColorStart[1], data.ColorStart[2], data.ColorStart[3]),
+		colorEnd:   rl.NewColor(data.ColorEnd[0], data.ColorEnd[1], data.ColorEnd[2], data.ColorEnd[3]),
+		drag:       data.Drag,
+		gravity:    data.Gravity,
+		spin:       data.Spin,
+		shape:      shape,
+	}
+}
+
+// Load the emitters from the data file, any emitter it doesn't define falls back to a plain puff
+func InitEmitters() {
+	for id := range emitterDefs {
+		emitterDefs[id] = fallbackEmitter
+	}
+
+	data, err := os.ReadFile(EMITTERS_FILE)
+	if err != nil {
+		rl.TraceLog(rl.LogError, "Failed to load emitters from %s! Every effect is a plain puff.", EMITTERS_FILE)
+		return
+	}
+	var file map[string]EmitterData
+	if err := json.Unmarshal(data, &file); err != nil {
+		rl.TraceLog(rl.LogError, "Failed to parse %s: %s", EMITTERS_FILE, err.Error())
+		return
+	}
+
+	for id, name := range emitterNames {
+		emitter, ok := file[name]
+		if !ok {
+			rl.TraceLog(rl.LogError, "Emitter %s is missing from %s! It is a plain puff.", name, EMITTERS_FILE)
+			continue
+		}
+		emitterDefs[id] = emitter.def()
+		delete(file, name)
+	}
+
+	// Whatever is left doesn't match any emitter
+	for name := range file {
+		rl.TraceLog(rl.LogWarning, "Unknown emitter %s in %s", name, EMITTERS_FILE)
+	}
+}
+
+// Particle is a single short-lived point of an effect
+type Particle struct {
+	pos, vel           rl.Vector2
+	life, maxLife      float32
+	rotation, spin     float32
+	sizeStart, sizeEnd float32
+	colorStart         rl.Color
+	colorEnd           rl.Color
+	drag               float32
+	gravity            float32
+	shape              ParticleShape
+}
+
+// ParticleSystem keeps all particles in a fixed pool, live ones packed at the front
+type ParticleSystem struct {
+	particles []Particle
+	alive     int
+}
+
+// Shared particle system used by every effect in the game
+var particles *ParticleSystem
+
+// NewParticleSystem creates a particle pool with a fixed capacity
+func NewParticleSystem(capacity int) *ParticleSystem {
+	return &ParticleSystem{
+		particles: make([]Particle, capacity),
+	}
+}
+
+// Emit a burst of particles from pos towards dir
+func (ps *ParticleSystem) Emit(id EmitterID, pos rl.Vector2, dir rl.Vector2) {
+	ps.EmitScaled(id, pos, dir, 1)
+}
+
+// Emit a burst with speed and size multiplied by scale (bigger explosions and so on)
+func (ps *ParticleSystem) EmitScaled(id EmitterID, pos rl.Vector2, dir rl.Vector2, scale float32) {
+	def := &emitterDefs[id]
+
+	// Angle the cone is centered on, any direction works for full circles
+	baseAngle := float32(0)
+	if dir.X != 0 || dir.Y != 0 {
+		baseAngle = float32(math.Atan2(float64(dir.Y), float64(dir.X)) * 180 / math.Pi)
+	}
+
+	count := int(rl.GetRandomValue(int32(def.countMin), int32(def.countMax)))
+	for i := 0; i < count; i++ {
+		// The pool is full, drop the rest of the burst instead of growing
+		if ps.alive >= len(ps.particles) {
+			return
+		}
+
+		angle := (baseAngle + randomFloat(-def.spread/2, def.spread/2)) * math.Pi / 180
+		speed := randomFloat(def.speedMin, def.speedMax) * scale
+		life := randomFloat(def.lifeMin, def.lifeMax)
+
+		p := &ps.particles[ps.alive]
+		*p = Particle{
+			pos:        pos,
+			vel:        rl.NewVector2(float32(math.Cos(float64(angle)))*speed, float32(math.Sin(float64(angle)))*speed),
+			life:       life,
+			maxLife:    life,
+			rotation:   randomFloat(0, 360),
+			spin:       randomFloat(-def.spin, def.spin),
+			sizeStart:  def.sizeStart * scale,
+			sizeEnd:    def.sizeEnd * scale,
+			colorStart: def.colorStart,
+			colorEnd:   def.colorEnd,
+			drag:       def.drag,
+			gravity:    def.gravity,
+			shape:      def.shape,
+		}
+		ps.alive++
+	}
+}
+
+// Move the particles and recycle the dead ones
+func (ps *ParticleSystem) Update(dt float64) {
+	fdt := float32(dt)
+
+	for i := 0; i < ps.alive; {
+		p := &ps.particles[i]
+		p.life -= fdt
+
+		if p.life <= 0 {
+			// Swap the last live particle into this slot, order doesn't matter
+			ps.alive--
+			ps.particles[i] = ps.particles[ps.alive]
+			continue
+		}
+
+		damping := 1 - p.drag*fdt
+		if damping < 0 {
+			damping = 0
+		}
+		p.vel = rl.Vector2Scale(p.vel, damping)
+		p.vel.Y += p.gravity * fdt
+		p.pos = rl.Vector2Add(p.pos, rl.Vector2Scale(p.vel, fdt))
+		p.rotation += p.spin * fdt
+
+		i++
+	}
+}
+
+// Draw all live particles
+func (ps *ParticleSystem) Render() {
+	for i := 0; i < ps.alive; i++ {
+		p := &ps.particles[i]
+
+		// 0 at birth, 1 at death
+		t := 1 - p.life/p.maxLife
+		size := p.sizeStart + (p.sizeEnd-p.sizeStart)*t
+		color := lerpColor(p.colorStart, p.colorEnd, t)
+
+		switch p.shape {
+		case ShapeRect:
+			rl.DrawRectanglePro(
+				rl.NewRectangle(p.pos.X, p.pos.Y, size*2, size),
+				rl.NewVector2(size, size/2),
+				p.rotation,
+				color,
+			)
+		default:
+			rl.DrawCircleV(p.pos, size, color)
+		}
+	}
+}
+
+// The particle system lives for the whole game
+func (ps *ParticleSystem) Destroyed() bool {
+	return false
+}
+
+// Remove every particle
+func (ps *ParticleSystem) Clear() {
+	ps.alive = 0
+}
+
+// Blend two colors, t goes from 0 (a) to 1 (b)
+func lerpColor(a, b rl.Color, t float32) rl.Color {
+	return rl.NewColor(
+		uint8(float32(a.R)+(float32(b.R)-float32(a.R))*t),
+		uint8(float32(a.G)+(float32(b.G)-float32(a.G))*t),
+		uint8(float32(a.B)+(float32(b.B)-float32(a.B))*t),
+		uint8(float32(a.A)+(float32(b.A)-float32(a.A))*t),
+	)
+}
+
+// Random float between min and max
+func randomFloat(min, max float32) float32 {
+	return min + (max-min)*float32(rl.GetRandomValue(0, 1000))/1000.0
+}
+
+// Particles, decals and lights for gameplay events
+func subscribeEffects(bus *EventBus) {
+	Subscribe(bus, func(e ShotFired) {
+		// Flash at the barrel and eject a casing to the side
+		particles.Emit(EmitterMuzzleFlash, e.Muzzle, e.Dir)
+		particles.Emit(EmitterShellCasing, e.Origin, rl.NewVector2(-e.Dir.Y, e.Dir.X))
+		lighting.AddLight(e.Muzzle, muzzleLightRadius, 0.8, muzzleLightDuration)
+	})
+	Subscribe(bus, func(e EnemyHit) {
+		particles.Emit(EmitterBloodSpray, e.Enemy.pos, e.Dir)
+	})
+	Subscribe(bus, func(e GrenadeExploded) {
+		// The explosion itself is drawn by the particle system
+		scale := e.Radius / 150
+
+		// Cryo grenades burst into frost instead of fire and leave no scorch
+		if e.Kind == GrenadeCryo {
+			particles.EmitScaled(EmitterFrost, e.Pos, rl.Vector2Zero(), scale)
+			lighting.AddLight(e.Pos, e.Radius*explosionLightScale, 0.6, explosionLightTime)
+			return
+		}
+
+		particles.EmitScaled(EmitterExplosionFire, e.Pos, rl.Vector2Zero(), scale)
+		particles.EmitScaled(EmitterExplosionDebris, e.Pos, rl.Vector2Zero(), scale)
+		particles.EmitScaled(EmitterSmoke, e.Pos, rl.Vector2Zero(), scale)
+		decals.StampScorch(e.Pos, e.Radius*0.5)
+		lighting.AddLight(e.Pos, e.Radius*explosionLightScale, 1, explosionLightTime)
+	})
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const PERKS_FILE = "assets/perks.json"
+
+// Stats perks can modify
+const (
+	PerkMaxHp         = "maxHp"
+	PerkReloadTime    = "reloadTime"
+	PerkFireDelay     = "fireDelay"
+	PerkProjectiles   = "projectiles"
+	PerkDamage        = "damage"
+	PerkGrenadeRadius = "grenadeRadius"
+	PerkMoveSpeed     = "moveSpeed"
+	PerkLifesteal     = "lifesteal"
+	PerkPierce        = "pierce"
+)
+
+var (
+	perkChoices    int   = 3 // Perks offered after each level
+	perkCardWidth  int32 = 260
+	perkCardHeight int32 = 110
+	perkCardGap    int32 = 30
+	perkIconSize   int32 = 40
+)
+
+// Keys picking each offered perk
+var perkKeys = []int32{rl.KeyOne, rl.KeyTwo, rl.KeyThree}
+
+// PerkDef is one perk as read from the data file. Every stack adds Add to the stat
+// and then multiplies it by Multiply
+type PerkDef struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Icon        string  `json:"icon"` // Two letters shown in the perk bar
+	Stat        string  `json:"stat"`
+	Add         float64 `json:"add,omitempty"`
+	Multiply    float64 `json:"multiply,omitempty"`
+	MaxStacks   int     `json:"maxStacks"` // How many times the perk can be taken, 0 for no limit
+}
+
+// Every perk that can be drafted
+var perkDefs []PerkDef
+
+// Load the perk definitions
+func InitPerks() {
+	data, err := os.ReadFile(PERKS_FILE)
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "Failed to load perks from %s! Perks are disabled.", PERKS_FILE)
+		return
+	}
+	if err := json.Unmarshal(data, &perkDefs); err != nil {
+		rl.TraceLog(rl.LogWarning, "Failed to parse %s: %s", PERKS_FILE, err.Error())
+		perkDefs = nil
+	}
+}
+
+// Perks is the modifier layer between the base stats and the ones used in play,
+// the base weapons and player stats are never changed
+type Perks struct {
+	stacks map[string]int     // Perk id to times taken
+	order  []*PerkDef         // Perks in the order they were first taken, for the perk bar
+	add    map[string]float64 // Flat bonus of every stat
+	mult   map[string]float64 // Multiplier of every stat
+}
+
+func NewPerks() Perks {
+	return Perks{
+		stacks: make(map[string]int),
+		add:    make(map[string]float64),
+		mult:   make(map[string]float64),
+	}
+}
+
+// Times the perk was taken
+func (ps *Perks) Stacks(def *PerkDef) int {
+	return ps.stacks[def.ID]
+}
+
+// Whether the perk can still be taken
+func (ps *Perks) CanTake(def *PerkDef) bool {
+	return def.MaxStacks == 0 || ps.stacks[def.ID] < def.MaxStacks
+}
+
+// Add a stack of the perk
+func (ps *Perks) Take(def *PerkDef) {
+	if ps.stacks[def.ID] == 0 {
+		ps.order = append(ps.order, def)
+	}
+	ps.stacks[def.ID]++
+
+	ps.add[def.Stat] += def.Add
+	if def.Multiply != 0 {
+		if ps.mult[def.Stat] == 0 {
+			ps.mult[def.Stat] = 1
+		}
+		ps.mult[def.Stat] *= def.Multiply
+	}
+}
+
+// Base value of a stat with the perks applied
+func (ps *Perks) Modify(stat string, base float64) float64 {
+	mult := ps.mult[stat]
+	if mult == 0 {
+		mult = 1
+	}
+	return (base + ps.add[stat]) * mult
+}
+
+// Take a perk, perks raising the max HP heal by the same amount
+func (p *player) TakePerk(def *PerkDef) {
+	before := p.MaxHp()
+	p.perks.Take(def)
+	if gained := p.MaxHp() - before; gained > 0 {
+		p.CurrentHp += gained
+	}
+}
+
+// The weapon in hand with the perks applied
+func (p *player) Weapon() weapon {
+	w := p.currentWeapon
+	w.shootingDelay = p.perks.Modify(PerkFireDelay, w.shootingDelay)
+	w.reloadTime = p.perks.Modify(PerkReloadTime, w.reloadTime)
+	w.projDamage = float32(p.perks.Modify(PerkDamage, float64(w.projDamage)))
+	w.nProj = int(p.perks.Modify(PerkProjectiles, float64(w.nProj)))
+	return w
+}
+
+func (p *player) MaxHp() int {
+	return int(p.perks.Modify(PerkMaxHp, float64(p.TotalHp)))
+}
+
+func (p *player) MoveSpeed() float32 {
+	return float32(p.perks.Modify(PerkMoveSpeed, float64(playerSpeed)))
+}
+
+// Blast radius of the player's grenades of a kind
+func (p *player) GrenadeRadius(kind GrenadeKind) float32 {
+	return float32(p.perks.Modify(PerkGrenadeRadius, float64(grenadeDefs[kind].radius)))
+}
+
+// Extra enemies a bullet goes through
+func (p *player) Pierce() int {
+	return int(p.perks.Modify(PerkPierce, 0))
+}
+
+// Heal a share of the damage dealt, fractions of HP are kept for the next hit
+func (p *player) Lifesteal(damage float32) {
+	share := float32(p.perks.Modify(PerkLifesteal, 0))
+	if share <= 0 {
+		return
+	}
+
+	p.lifestealPool += damage * share
+	heal := int(p.lifestealPool)
+	p.lifestealPool -= float32(heal)
+	p.Heal(heal)
+}
+
+// Draw the taken perks as a row of icons ending at x, stacks in the corner
+func (p *player) RenderPerks(x, y int32) {
+	for i := len(p.perks.order) - 1; i >= 0; i-- {
+		def := p.perks.order[i]
+		x -= perkIconSize + 6
+
+		rl.DrawRectangle(x, y, perkIconSize, perkIconSize, rl.ColorAlpha(rl.DarkGray, 0.8))
+		rl.DrawRectangleLines(x, y, perkIconSize, perkIconSize, rl.Gold)
+
+		iconWidth := rl.MeasureText(def.Icon, 18)
+		rl.DrawText(def.Icon, x+perkIconSize/2-iconWidth/2, y+8, 18, rl.White)
+
+		if stacks := p.perks.Stacks(def); stacks > 1 {
+			stackText := fmt.Sprintf("x%d", stacks)
+			stackWidth := rl.MeasureText(stackText, 12)
+			rl.DrawText(stackText, x+perkIconSize-stackWidth-3, y+perkIconSize-13, 12, rl.Gold)
+		}
+	}
+}
+
+// PerkDraft offers a choice of perks between levels
+type PerkDraft struct {
+	choices []*PerkDef
+}
+
+// Offer random perks the player can still take, none are offered once every perk is maxed
+func (d *PerkDraft) Start(p *player) {
+	d.choices = d.choices[:0]
+
+	var pool []*PerkDef
+	for i := range perkDefs {
+		if p.perks.CanTake(&perkDefs[i]) {
+			pool = append(pool, &perkDefs[i])
+		}
+	}
+
+	// Draw without putting back so the same perk isn't offered twice
+	for len(d.choices) < perkChoices && len(pool) > 0 {
+		i := RandomValue(0, int32(len(pool))-1)
+		d.choices = append(d.choices, pool[i])
+		pool = append(pool[:i], pool[i+1:]...)
+	}
+}
+
+// Whether the player still has to pick a perk
+func (d *PerkDraft) Active() bool {
+	return len(d.choices) > 0
+}
+
+// Drop the offer without picking
+func (d *PerkDraft) Clear() {
+	d.choices = d.choices[:0]
+}
+
+// Where the card of a choice is drawn
+func (d *PerkDraft) cardRect(i int, screenWidth, screenHeight int32) rl.Rectangle {
+	total := int32(len(d.choices))*(perkCardWidth+perkCardGap) - perkCardGap
+	x := screenWidth/2 - total/2 + int32(i)*(perkCardWidth+perkCardGap)
+	y := screenHeight/2 + 130
+	return rl.NewRectangle(float32(x), float32(y), float32(perkCardWidth), float32(perkCardHeight))
+}
+
+// Pick a perk with the number keys or by clicking its card
+func (d *PerkDraft) Update(p *player, screenWidth, screenHeight int32) {
+	picked := -1
+	for i := range d.choices {
+		if rl.IsKeyPressed(perkKeys[i]) {
+			picked = i
+		}
+		if rl.IsMouseButtonPressed(rl.MouseLeftButton) && rl.CheckCollisionPointRec(rl.GetMousePosition(), d.cardRect(i, screenWidth, screenHeight)) {
+			picked = i
+		}
+	}
+
+	if picked >= 0 {
+		p.TakePerk(d.choices[picked])
+		PlaySFX(SoundPickup)
+		d.Clear()
+	}
+}
+
+// Draw the offered perks as cards, the hovered one highlighted
+func (d *PerkDraft) Render(p *player, screenWidth, screenHeight int32) {
+	headerText := "CHOOSE A PERK (1-3 or click)"
+	headerWidth := rl.MeasureText(headerText, 25)
+	rl.DrawText(headerText, screenWidth/2-headerWidth/2, screenHeight/2+95, 25, rl.Gold)
+
+	for i, def := range d.choices {
+		rect := d.cardRect(i, screenWidth, screenHeight)
+		x, y := int32(rect.X), int32(rect.Y)
+
+		border := rl.Gray
+		if rl.CheckCollisionPointRec(rl.GetMousePosition(), rect) {
+			border = rl.Gold
+		}
+		rl.DrawRectangleRec(rect, rl.ColorAlpha(rl.Black, 0.85))
+		rl.DrawRectangleLinesEx(rect, 2, border)
+
+		rl.DrawText(fmt.Sprintf("[%d] %s", i+1, def.Name), x+12, y+12, 22, rl.White)
+		rl.DrawText(def.Description, x+12, y+45, 16, rl.LightGray)
+
+		if def.MaxStacks > 0 {
+			stackText := fmt.Sprintf("%d / %d", p.perks.Stacks(def), def.MaxStacks)
+			rl.DrawText(stackText, x+12, y+perkCardHeight-26, 16, rl.Gray)
+		}
+	}
+}
@@ -12,28 +12,40 @@ type player struct {
 	Pos       rl.Vector2
 
 	currentWeapon   weapon
-	ammo            int     // Total ammo in inventory
-	currentMagazine int     // Current ammo in magazine
-	isReloading     bool    // Whether player is currently reloading
-	reloadStartTime float64 // When reload started
+	inventory       Inventory          // Carried weapons, currentWeapon is the one in hand
+	ammo            [ammoTypeCount]int // Spare rounds of every type
+	currentMagazine int                // Current ammo in magazine
+	isReloading     bool               // Whether player is currently reloading
+	reloadStartTime float64            // When reload started
 
-	grenades int // Number of grenades player has
+	grenades    [grenadeKindCount]int // Number of grenades of every kind the player has
+	grenadeKind GrenadeKind           // Kind thrown next
 
-	lookAt    rl.Vector2
-	lookAtSet bool
+	invulnerableUntil float64    // Hits are ignored until this time
+	knockback         rl.Vector2 // Speed the last hit threw the player back with
+	hitTime           float64    // When the player was last hit, for the red flash
+	hitDir            rl.Vector2 // Direction the last hit came from
+
+	coins int // Currency dropped by zombies, spent in the shop
+	armor int // Soaks up damage before health
+
+	regenPool float32 // Regeneration not applied yet, HP are whole numbers
+
+	stamina        float32    // Used by sprinting and dodge rolls
+	lastStaminaUse float64    // Stamina only recovers a while after it was used
+	dodgeDir       rl.Vector2 // Direction of the current roll
+	dodgeUntil     float64    // When the current roll ends
+	nextDodge      float64    // When the player can roll again
 
-	// Player sprite textures
-	spriteLeft  rl.Texture2D
-	spriteRight rl.Texture2D
-	facingLeft  bool // Track player direction for sprite selection
+	perks         Perks   // Taken perks, applied on top of the base stats
+	lifestealPool float32 // Healing from lifesteal not applied yet, HP are whole numbers
 
-	// Weapon pickup notification
-	weaponPickupTime float64
-	weaponPickupName string
+	lookAt    rl.Vector2
+	lookAtSet bool
 
-	// Ammo pickup notification
-	ammoPickupTime   float64
-	ammoPickupAmount int
+	anim       Animator // Current animation clip
+	facingLeft bool     // Track player direction for sprite selection
+	dying      bool     // Playing the death clip before the game over screen
 }
 
 type weapon struct {
@@ -41,69 +53,131 @@ type weapon struct {
 	shootingDelay float64
 	projDamage    float32
 	nProj         int
-	usesAmmo      bool    // Whether this weapon uses ammo
-	magazineSize  int     // How many bullets in a full magazine
-	reloadTime    float64 // How long it takes to reload in seconds
+	usesAmmo      bool     // Whether this weapon uses ammo
+	ammoType      AmmoType // Rounds the weapon loads
+	magazineSize  int      // How many bullets in a full magazine
+	reloadTime    float64  // How long it takes to reload in seconds
+	shotSound     SoundID  // Sound played when the weapon fires
 }
 
 var (
-	PISTOL  weapon = weapon{shootingDelay: 0.5, projDamage: 50, nProj: 1, weaponName: "Pistol", usesAmmo: false, magazineSize: 12, reloadTime: 1.0}
-	MITRA   weapon = weapon{shootingDelay: 0.1, projDamage: 500, nProj: 1, weaponName: "Mitra", usesAmmo: true, magazineSize: 30, reloadTime: 1.5}
-	SHOTGUN weapon = weapon{shootingDelay: 0.8, projDamage: 30, nProj: 5, weaponName: "Shotgun", usesAmmo: true, magazineSize: 8, reloadTime: 2.0}    // Shoots multiple projectiles
-	MINIGUN weapon = weapon{shootingDelay: 0.05, projDamage: 15, nProj: 1, weaponName: "Minigun", usesAmmo: true, magazineSize: 100, reloadTime: 3.0} // Very fast firing rate
+	PISTOL  weapon = weapon{shootingDelay: 0.5, projDamage: 50, nProj: 1, weaponName: "Pistol", usesAmmo: true, ammoType: AmmoPistol, magazineSize: 12, reloadTime: 1.0, shotSound: SoundPistolShot}
+	MITRA   weapon = weapon{shootingDelay: 0.1, projDamage: 500, nProj: 1, weaponName: "Mitra", usesAmmo: true, ammoType: AmmoRifle, magazineSize: 30, reloadTime: 1.5, shotSound: SoundMitraShot}
+	SHOTGUN weapon = weapon{shootingDelay: 0.8, projDamage: 30, nProj: 5, weaponName: "Shotgun", usesAmmo: true, ammoType: AmmoShells, magazineSize: 8, reloadTime: 2.0, shotSound: SoundShotgunShot}  // Shoots multiple projectiles
+	MINIGUN weapon = weapon{shootingDelay: 0.05, projDamage: 15, nProj: 1, weaponName: "Minigun", usesAmmo: true, ammoType: AmmoBelt, magazineSize: 100, reloadTime: 3.0, shotSound: SoundMinigunShot} // Very fast firing rate
 )
 
-var playerSpeed float32 = 300
+// Player sprite sheets, shared by every run. Left and right point to the same sheet
+// when it faces right and gets mirrored
+var (
+	playerSheetLeft  *SpriteSheet
+	playerSheetRight *SpriteSheet
+	playerSheetFront *SpriteSheet // Used while aiming down, nil without the sheet
+	playerMirrorLeft bool         // Whether the left sheet has to be mirrored
+)
 
-func NewPlayer(totalHp int) player {
-	// Load player sprites
-	var spriteLeft, spriteRight rl.Texture2D
+var playerSpeed float32 = 300
+var maxArmor int = 200 // Most armor the player can wear
 
-	// Try different possible paths for the sprites
-	path := "assets/player_left.png"
+var (
+	playerInvulnerableTime float64 = 0.6 // Seconds without damage after a hit
+	playerKnockback        float32 = 450 // Speed a hit throws the player back with
+	playerKnockbackDrag    float32 = 10  // Fraction of the knockback speed lost per second
+	hitFlashTime           float64 = 0.3 // Seconds the screen flashes red after a hit
+	hitIndicatorTime       float64 = 1.0 // Seconds the direction of a hit stays shown
+)
 
+// Initialize the player sprites
+func InitPlayerSprites() {
 	// Print working directory for debugging
 	rl.TraceLog(rl.LogWarning, "Loading player sprites...")
 
-	spriteLoaded := false
-	// Try to load the left sprite
-	spriteLeft = rl.LoadTexture(path)
-
-	// Check if left sprite loaded successfully
-	if spriteLeft.ID > 0 {
-		// Now try to load the right sprite
-		rightPath := "assets/player_right.png"
-		spriteRight = rl.LoadTexture(rightPath)
+	// Prefer the animated sheet, mirrored when facing left
+	playerSheetRight = LoadSpriteSheet(playerSheetDef, "")
+	playerSheetLeft = playerSheetRight
+	playerMirrorLeft = true
 
-		// Check if right texture loaded successfully
-		if spriteRight.ID > 0 {
-			rl.TraceLog(rl.LogInfo, "Successfully loaded sprites from %s and %s", path, rightPath)
-			spriteLoaded = true
-		}
+	if playerSheetRight == nil {
+		// Fall back to the two static sprites, one for each direction
+		playerSheetLeft = LoadSpriteSheet(playerSheetDef, "assets/player_left.png")
+		playerSheetRight = LoadSpriteSheet(playerSheetDef, "assets/player_right.png")
+		playerMirrorLeft = false
 	}
 
-	if !spriteLoaded {
+	if playerSheetLeft == nil || playerSheetRight == nil {
 		rl.TraceLog(rl.LogWarning, "Failed to load player sprites! Will use fallback circle.")
 	}
 
-	return player{
-		TotalHp:          totalHp,
-		CurrentHp:        totalHp,
-		Pos:              rl.NewVector2(500, 500),
-		lookAt:           rl.NewVector2(0, 0),
-		currentWeapon:    PISTOL,
-		lookAtSet:        false,
-		weaponPickupName: "",
-		weaponPickupTime: 0,
-		ammo:             50,
-		currentMagazine:  PISTOL.magazineSize, // Start with full magazine
-		isReloading:      false,
-		ammoPickupTime:   0,
-		ammoPickupAmount: 0,
-		grenades:         3, // Start with 3 grenades
-		spriteLeft:       spriteLeft,
-		spriteRight:      spriteRight,
-		facingLeft:       false,
+	playerSheetFront = LoadSpriteSheet(playerFrontSheetDef, "")
+}
+
+// Unload the player sprites
+func UnloadPlayerSprites() {
+	playerSheetLeft.Unload()
+	if playerSheetRight != playerSheetLeft {
+		playerSheetRight.Unload()
+	}
+	playerSheetFront.Unload()
+}
+
+func NewPlayer(totalHp int) player {
+	p := player{
+		TotalHp:         totalHp,
+		CurrentHp:       totalHp,
+		Pos:             rl.NewVector2(500, 500),
+		lookAt:          rl.NewVector2(0, 0),
+		currentWeapon:   PISTOL,
+		inventory:       NewInventory(),
+		lookAtSet:       false,
+		ammo:            startingAmmo(),
+		currentMagazine: PISTOL.magazineSize, // Start with full magazine
+		isReloading:     false,
+		grenades:        startingGrenades(),
+		perks:           NewPerks(),
+		stamina:         maxStamina,
+		facingLeft:      false,
+	}
+	p.anim.Play(playerSheetRight, ClipIdle)
+	return p
+}
+
+// Start the death clip, the game is over once it has finished
+func (p *player) Kill() {
+	if p.dying {
+		return
+	}
+	p.dying = true
+	p.isReloading = false
+	p.knockback = rl.Vector2Zero()
+	p.anim.Restart(playerSheetRight, ClipDeath)
+}
+
+// Whether the death clip has finished and the game over screen can come up
+func (p *player) DeathFinished() bool {
+	return p.dying && p.anim.Finished()
+}
+
+// Pick the animation clip from what the player is doing
+func (p *player) UpdateAnimation(dt float64, moving bool) {
+	p.anim.Update(dt)
+
+	if p.dying {
+		return
+	}
+
+	// One-shot clips play to the end before anything else takes over
+	clip := p.anim.Clip()
+	if (clip == ClipHurt || clip == ClipShoot) && !p.anim.Finished() {
+		return
+	}
+
+	switch {
+	case p.isReloading:
+		p.anim.Play(playerSheetRight, ClipReload)
+	case moving:
+		p.anim.Play(playerSheetRight, ClipWalk)
+	default:
+		p.anim.Play(playerSheetRight, ClipIdle)
 	}
 }
 
@@ -113,7 +187,7 @@ func (p *player) LookAt(lookAt rl.Vector2) {
 }
 
 func (p *player) Update(dt float64, currentTime float64) {
-	dtSpeed := playerSpeed * float32(dt)
+	dtSpeed := p.MoveSpeed() * float32(dt)
 
 	// Track movement for sprite direction
 	moving := false
@@ -146,7 +220,7 @@ func (p *player) Update(dt float64, currentTime float64) {
 
 	// Calculate player's effective size (taking into account the sprite scaling)
 	var playerBoundarySize float32
-	spritesLoaded := p.spriteLeft.ID > 0 && p.spriteRight.ID > 0
+	spritesLoaded := playerSheetLeft != nil && playerSheetRight != nil
 	if spritesLoaded {
 		// Use the standard size for boundary calculations
 		playerBoundarySize = playerSize * 1.6 // A bit smaller than the actual sprite for better feel
@@ -181,22 +255,16 @@ func (p *player) Update(dt float64, currentTime float64) {
 	// Update reload progress
 	if p.isReloading {
 		// Check if reload is complete
-		if currentTime >= p.reloadStartTime+p.currentWeapon.reloadTime {
+		if currentTime >= p.reloadStartTime+p.Weapon().reloadTime {
 			p.isReloading = false
+			PlaySFXAt(SoundReloadFinish, p.Pos)
 
 			// Calculate how many bullets to add to magazine
 			bulletsNeeded := p.currentWeapon.magazineSize - p.currentMagazine
 
 			if p.currentWeapon.usesAmmo {
-				// If we have enough ammo, add full magazine
-				if p.ammo >= bulletsNeeded {
-					p.ammo -= bulletsNeeded
-					p.currentMagazine = p.currentWeapon.magazineSize
-				} else {
-					// Otherwise add whatever we have left
-					p.currentMagazine += p.ammo
-					p.ammo = 0
-				}
+				// Fill the magazine with whatever is left of the matching rounds
+				p.currentMagazine += p.TakeAmmo(p.currentWeapon.ammoType, bulletsNeeded)
 			} else {
 				// If weapon doesn't use ammo, just fill the magazine
 				p.currentMagazine = p.currentWeapon.magazineSize
@@ -225,22 +293,16 @@ func (p *player) UpdateWithoutMovement(dt float64, currentTime float64) {
 	// Update reload progress
 	if p.isReloading {
 		// Check if reload is complete
-		if currentTime >= p.reloadStartTime+p.currentWeapon.reloadTime {
+		if currentTime >= p.reloadStartTime+p.Weapon().reloadTime {
 			p.isReloading = false
+			PlaySFXAt(SoundReloadFinish, p.Pos)
 
 			// Calculate how many bullets to add to magazine
 			bulletsNeeded := p.currentWeapon.magazineSize - p.currentMagazine
 
 			if p.currentWeapon.usesAmmo {
-				// If we have enough ammo, add full magazine
-				if p.ammo >= bulletsNeeded {
-					p.ammo -= bulletsNeeded
-					p.currentMagazine = p.currentWeapon.magazineSize
-				} else {
-					// Otherwise add whatever we have left
-					p.currentMagazine += p.ammo
-					p.ammo = 0
-				}
+				// Fill the magazine with whatever is left of the matching rounds
+				p.currentMagazine += p.TakeAmmo(p.currentWeapon.ammoType, bulletsNeeded)
 			} else {
 				// If weapon doesn't use ammo, just fill the magazine
 				p.currentMagazine = p.currentWeapon.magazineSize
@@ -253,40 +315,41 @@ func (p *player) UpdateWithoutMovement(dt float64, currentTime float64) {
 
 func (p *player) Render() {
 	// Check if sprites were loaded successfully
-	spritesLoaded := p.spriteLeft.ID > 0 && p.spriteRight.ID > 0
+	spritesLoaded := playerSheetLeft != nil && playerSheetRight != nil
 
 	if spritesLoaded {
 		// Render player sprite
-		sprite := p.spriteRight
+		sheet := playerSheetRight
+		mirror := false
 		if p.facingLeft {
-			sprite = p.spriteLeft
+			sheet = playerSheetLeft
+			mirror = playerMirrorLeft
+		}
+
+		// Face the camera while aiming down, the front sheet is never mirrored
+		if playerSheetFront != nil && p.lookAtSet && p.lookAt.Y > float32(math.Abs(float64(p.lookAt.X))) {
+			sheet = playerSheetFront
+			mirror = false
 		}
 
 		// Size to draw the sprite (scale it according to playerSize)
-		spriteScale := playerSize / float32(sprite.Height) * 4.0
-		width := float32(sprite.Width) * spriteScale
-		height := float32(sprite.Height) * spriteScale
-
-		// Draw the sprite centered on player position
-		rl.DrawTexturePro(
-			sprite,
-			rl.NewRectangle(0, 0, float32(sprite.Width), float32(sprite.Height)),
-			rl.NewRectangle(p.Pos.X-width/2, p.Pos.Y-height/2, width, height),
-			rl.NewVector2(0, 0),
-			0,
-			rl.White,
-		)
+		height := playerSize * 4.0
+
+		// Draw the current frame centered on player position
+		sheet.Draw(&p.anim, p.Pos, height, mirror, p.tint())
 
 		// Draw health bar above player
 		healthBarY := p.Pos.Y - height/2 - 10
 		drawHealthBar(p, healthBarY)
+		drawStaminaBar(p, healthBarY+8)
 	} else {
 		// Fallback to circle if sprites not loaded
-		rl.DrawCircle(int32(p.Pos.X), int32(p.Pos.Y), playerSize*1.6, rl.Red)
+		rl.DrawCircle(int32(p.Pos.X), int32(p.Pos.Y), playerSize*1.6, rl.ColorAlpha(rl.Red, float32(p.tint().A)/255))
 
 		// Draw health bar above circle
 		healthBarY := p.Pos.Y - playerSize*1.6 - 10
 		drawHealthBar(p, healthBarY)
+		drawStaminaBar(p, healthBarY+8)
 	}
 
 	// Draw direction indicator if needed
@@ -306,7 +369,7 @@ func (p *player) Render() {
 func drawHealthBar(p *player, yPosition float32) {
 	healthBarWidth := playerSize * 3 // Increased from 2 to 3 for wider health bar
 	healthBarHeight := 6.0           // Increased from 5.0 to 6.0 for taller health bar
-	healthPercentage := float32(p.CurrentHp) / float32(p.TotalHp)
+	healthPercentage := float32(p.CurrentHp) / float32(p.MaxHp())
 
 	// Background of health bar
 	rl.DrawRectangle(
@@ -328,27 +391,36 @@ func drawHealthBar(p *player, yPosition float32) {
 }
 
 func (p *player) Shoot() []*Projectile {
-	// Can't shoot while reloading
-	if p.isReloading {
+	// Can't shoot while reloading or switching weapons
+	if p.isReloading || p.IsSwitching(rl.GetTime()) {
 		return nil
 	}
 
-	// Switch to pistol if out of ammo and trying to use a weapon that requires ammo
-	if p.currentMagazine <= 0 && p.ammo <= 0 && p.currentWeapon.usesAmmo {
-		p.currentWeapon = PISTOL
-		p.currentMagazine = PISTOL.magazineSize
-		p.weaponPickupTime = rl.GetTime()
-		p.weaponPickupName = "Pistol (Out of ammo!)"
+	// Take the pistol out if out of ammo, the empty weapon stays in the inventory
+	if p.currentMagazine <= 0 && p.Ammo() <= 0 && p.currentWeapon.usesAmmo {
+		if p.EquipSlot(0, rl.GetTime()) {
+			Publish(events, WeaponEquipped{Name: "Pistol (Out of ammo!)", Time: rl.GetTime()})
+		}
+		return nil
 	}
 
 	var projs []*Projectile
+	w := p.Weapon()
 
 	// Only shoot if we have ammo in magazine
-	if p.currentMagazine > 0 || !p.currentWeapon.usesAmmo {
-		for i := 0; i < p.currentWeapon.nProj; i++ {
-			noise := rl.GetRandomValue(-100, 100)
+	if p.currentMagazine > 0 || !w.usesAmmo {
+		for i := 0; i < w.nProj; i++ {
+			noise := RandomValue(-100, 100)
 			noisedDirection := rl.Vector2Add(rl.GetMousePosition(), rl.NewVector2(float32(noise), float32(noise)))
-			projs = append(projs, NewProj(p.Pos, noisedDirection, p.currentWeapon.projDamage))
+			proj := NewProj(p.Pos, noisedDirection, w.projDamage)
+			proj.weaponName = w.weaponName
+			proj.pierce = p.Pierce()
+			projs = append(projs, proj)
+		}
+
+		// Don't cut a hurt flinch short with the recoil
+		if p.anim.Clip() != ClipHurt || p.anim.Finished() {
+			p.anim.Restart(playerSheetRight, ClipShoot)
 		}
 
 		// Consume ammo from magazine if this weapon uses it
@@ -364,11 +436,97 @@ func (p *player) Position() rl.Vector2 {
 	return p.Pos
 }
 
+// The player is never removed from the world
+func (p *player) Destroyed() bool {
+	return false
+}
+
+// Take a hit from source, returns false if the player is dying or still invulnerable from the last one
+func (p *player) Hit(damage float32, source rl.Vector2, currentTime float64) bool {
+	if p.dying || currentTime < p.invulnerableUntil {
+		return false
+	}
+
+	p.TakeDamage(damage)
+	p.invulnerableUntil = currentTime + playerInvulnerableTime
+	p.hitTime = currentTime
+
+	// Thrown away from whatever hit
+	away := rl.Vector2Subtract(p.Pos, source)
+	if away.X == 0 && away.Y == 0 {
+		away = rl.NewVector2(0, 1)
+	}
+	away = rl.Vector2Normalize(away)
+	p.hitDir = rl.Vector2Negate(away)
+	p.knockback = rl.Vector2Scale(away, playerKnockback)
+	return true
+}
+
+// Slide along the knockback of the last hit, blocks and the screen edges stop it
+func (p *player) UpdateKnockback(dt float64, blocks []*Block) {
+	if p.knockback.X == 0 && p.knockback.Y == 0 {
+		return
+	}
+
+	// Slide along a block that stops the push, the push is over once it hits one
+	half := playerSize * 0.7
+	pushed, hit := moveCircle(p.Pos, rl.Vector2Scale(p.knockback, float32(dt)), half, blocks)
+	pushed.X = rl.Clamp(pushed.X, half, float32(rl.GetScreenWidth())-half)
+	pushed.Y = rl.Clamp(pushed.Y, half, float32(rl.GetScreenHeight())-half)
+	p.Pos = pushed
+	if hit {
+		p.knockback = rl.Vector2Zero()
+		return
+	}
+
+	p.knockback = rl.Vector2Scale(p.knockback, rl.Clamp(1-playerKnockbackDrag*float32(dt), 0, 1))
+	if rl.Vector2Length(p.knockback) < 20 {
+		p.knockback = rl.Vector2Zero()
+	}
+}
+
+// Blink while invulnerable
+func (p *player) tint() rl.Color {
+	now := rl.GetTime()
+	if now < p.invulnerableUntil && int(now*15)%2 == 0 {
+		return rl.ColorAlpha(rl.White, 0.4)
+	}
+	return rl.White
+}
+
+// Flash the screen red after a hit and point an arc around the player at where it came from
+func (p *player) RenderHitFeedback(screenWidth, screenHeight int32, currentTime float64) {
+	if p.hitTime == 0 {
+		return
+	}
+	since := currentTime - p.hitTime
+
+	if since < hitFlashTime {
+		alpha := float32(0.35 * (1 - since/hitFlashTime))
+		rl.DrawRectangle(0, 0, screenWidth, screenHeight, rl.ColorAlpha(rl.Red, alpha))
+	}
+
+	if since < hitIndicatorTime {
+		alpha := float32(1 - since/hitIndicatorTime)
+		angle := float32(math.Atan2(float64(p.hitDir.Y), float64(p.hitDir.X)) * 180 / math.Pi)
+		radius := playerSize * 3
+		rl.DrawRing(p.Pos, radius, radius+6, angle-30, angle+30, 16, rl.ColorAlpha(rl.Red, alpha))
+	}
+}
+
 func (p *player) TakeDamage(damage float32) {
+	// Armor soaks up its share of the damage before health does
+	damage = p.absorbWithArmor(damage)
+
 	p.CurrentHp -= int(damage)
 	if p.CurrentHp < 0 {
 		p.CurrentHp = 0
 	}
+
+	// Restart the flinch only once the previous one has finished
+	if p.anim.Clip() != ClipHurt || p.anim.Finished() {
+		p.anim.Restart(playerSheetRight, ClipHurt)
+	}
 }
 
 func (p *player) CheckCollision(other Collides) bool {
@@ -399,19 +557,23 @@ func (p *player) Reload(currentTime float64) bool {
 		return false
 	}
 
-	// Don't reload if pistol (infinite ammo)
+	// Weapons without ammo refill the magazine right away
 	if !p.currentWeapon.usesAmmo {
 		p.currentMagazine = p.currentWeapon.magazineSize
+		PlaySFXAt(SoundReloadFinish, p.Pos)
+		Publish(events, ReloadStarted{Weapon: p.currentWeapon.weaponName})
 		return true
 	}
 
 	// Don't reload if no ammo in inventory
-	if p.ammo <= 0 {
+	if p.Ammo() <= 0 {
 		return false
 	}
 
 	// Start reload
 	p.isReloading = true
 	p.reloadStartTime = currentTime
+	PlaySFXAt(SoundReloadStart, p.Pos)
+	Publish(events, ReloadStarted{Weapon: p.currentWeapon.weaponName})
 	return true
 }
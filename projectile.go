@@ -41,10 +41,16 @@ func UnloadBulletSprite() {
 }
 
 type Projectile struct {
-	damage    float32
-	dir       rl.Vector2
-	pos       rl.Vector2
-	destroyed bool
+	damage     float32
+	dir        rl.Vector2
+	pos        rl.Vector2
+	destroyed  bool
+	weaponName string  // Weapon that fired it, for the stats
+	maxRange   float32 // Distance it flies before falling, 0 flies on forever
+	travelled  float32
+	shrapnel   bool     // Thrown out by a frag grenade, doesn't count towards accuracy
+	pierce     int      // Extra enemies it goes through
+	hits       []*Enemy // Enemies already hit, so one isn't hit again while the bullet passes through
 }
 
 func NewProj(initialPos rl.Vector2, direction rl.Vector2, damage float32) *Projectile {
@@ -58,6 +64,16 @@ func NewProj(initialPos rl.Vector2, direction rl.Vector2, damage float32) *Proje
 	}
 }
 
+// Whether the projectile already went through the enemy
+func (p *Projectile) HasHit(e *Enemy) bool {
+	for _, hit := range p.hits {
+		if hit == e {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Projectile) Destroyed() bool {
 	return p.destroyed
 }
@@ -67,6 +83,14 @@ func (p *Projectile) Update(dt float64) {
 	dir := rl.Vector2Scale(p.dir, float32(dtspeed))
 	p.pos = rl.Vector2Add(p.pos, dir)
 
+	// Short range projectiles fall once they've flown far enough
+	if p.maxRange > 0 {
+		p.travelled += float32(dtspeed)
+		if p.travelled >= p.maxRange {
+			p.destroyed = true
+		}
+	}
+
 	// p.hitbox.X = p.pos.X
 	// p.hitbox.Y = p.pos.Y
 }
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Gameplay randomness comes from a seeded source so every run has a seed
+// that can be recorded, effects keep using raylib's own generator
+var (
+	runSeed int64
+	runRand = rand.New(rand.NewSource(1))
+)
+
+// Start a new run with a fresh seed
+func NewRunSeed() int64 {
+	SeedRun(time.Now().UnixNano())
+	return runSeed
+}
+
+// Seed the gameplay random generator
+func SeedRun(seed int64) {
+	runSeed = seed
+	runRand = rand.New(rand.NewSource(seed))
+}
+
+// Random int between min and max (both included), like rl.GetRandomValue
+func RandomValue(min, max int32) int32 {
+	if min > max {
+		min, max = max, min
+	}
+	return min + runRand.Int31n(max-min+1)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"sort"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// RenderLayer decides the order things are drawn in, lower layers first
+type RenderLayer int
+
+const (
+	LayerGround      RenderLayer = iota // Floor and static geometry
+	LayerDecals                         // Blood, scorch marks, bullet holes
+	LayerPickups                        // Loot lying on the floor
+	LayerActors                         // Player and enemies, sorted by Y
+	LayerProjectiles                    // Bullets and grenades
+	LayerEffects                        // Particles
+	LayerLighting                       // Darkness and lights on night levels
+	LayerHUD                            // Screen space text and indicators
+	layerCount
+)
+
+// Layered is implemented by world items that know which layer they are drawn on
+type Layered interface {
+	Layer() RenderLayer
+}
+
+// positioned items can be depth sorted
+type positioned interface {
+	Position() rl.Vector2
+}
+
+// RenderQueue collects world items each frame and draws them layer by layer
+type RenderQueue struct {
+	layers [layerCount][]WorldItem
+}
+
+// Empty the queue, keeping the memory for the next frame
+func (q *RenderQueue) Reset() {
+	for i := range q.layers {
+		q.layers[i] = q.layers[i][:0]
+	}
+}
+
+// Add an item to the layer it asks for, items without one go with the effects
+func (q *RenderQueue) Add(item WorldItem) {
+	layer := LayerEffects
+	if l, ok := item.(Layered); ok {
+		layer = l.Layer()
+	}
+	q.layers[layer] = append(q.layers[layer], item)
+}
+
+// Draw every layer from first to last, both included
+func (q *RenderQueue) Draw(first, last RenderLayer) {
+	for layer := first; layer <= last; layer++ {
+		items := q.layers[layer]
+
+		// Things lower on screen are closer to the camera and go on top
+		if layer == LayerActors {
+			sort.SliceStable(items, func(i, j int) bool {
+				return sortY(items[i]) < sortY(items[j])
+			})
+		}
+
+		for _, item := range items {
+			item.Render()
+		}
+	}
+}
+
+func sortY(item WorldItem) float32 {
+	if p, ok := item.(positioned); ok {
+		return p.Position().Y
+	}
+	return 0
+}
+
+func (b *Block) Layer() RenderLayer           { return LayerGround }
+func (d *DecalLayer) Layer() RenderLayer      { return LayerDecals }
+func (l *WeaponLoot) Layer() RenderLayer      { return LayerPickups }
+func (l *AmmoLoot) Layer() RenderLayer        { return LayerPickups }
+func (l *CoinLoot) Layer() RenderLayer        { return LayerPickups }
+func (m *MedkitPickup) Layer() RenderLayer    { return LayerPickups }
+func (g *GrenadePickup) Layer() RenderLayer   { return LayerPickups }
+func (f *FireZone) Layer() RenderLayer        { return LayerDecals }
+func (e *Enemy) Layer() RenderLayer           { return LayerActors }
+func (p *player) Layer() RenderLayer          { return LayerActors }
+func (p *Projectile) Layer() RenderLayer      { return LayerProjectiles }
+func (g *Grenade) Layer() RenderLayer         { return LayerProjectiles }
+func (ps *ParticleSystem) Layer() RenderLayer { return LayerEffects }
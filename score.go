@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+var (
+	pointsPerKill         int     = 100  // Base points for a kill, multiplied by the enemy level
+	comboWindow           float64 = 2.0  // Seconds to land the next kill and keep the chain going
+	comboStep             float32 = 0.25 // Multiplier gained with every chained kill
+	maxMultiplier         float32 = 8.0
+	multiplierDecay       float32 = 1.5  // Multiplier lost per second once the chain is broken
+	multiKillBonus        int     = 250  // Bonus for every kill after the first with one grenade
+	noDamageBonusPerLevel int     = 500  // Bonus for clearing a level without taking damage, times the level
+	fastClearParPerEnemy  float64 = 3.0  // Par time of a level in seconds per enemy
+	fastClearBonusPerSec  int     = 50   // Bonus for every second under par
+	popupDuration         float32 = 1.0  // How long score pop-ups stay on screen
+	popupRiseSpeed        float32 = 40.0 // How fast pop-ups float up in pixels per second
+)
+
+// ScorePopup is a piece of text floating up from where points were scored
+type ScorePopup struct {
+	text  string
+	pos   rl.Vector2
+	color rl.Color
+	life  float32
+}
+
+// ScoreSystem turns kills and level clears into points
+type ScoreSystem struct {
+	score            int
+	multiplier       float32
+	chain            int     // Kills in the current chain
+	lastKillTime     float64 // When the chain was last extended
+	levelTime        float64 // Unpaused time spent on the current level
+	levelDamageTaken bool
+	popups           []ScorePopup
+}
+
+// Shared score for the current run
+var scoring ScoreSystem
+
+// Score kills and level clears from gameplay events
+func subscribeScoring(bus *EventBus) {
+	Subscribe(bus, func(e EnemyKilled) {
+		scoring.AddKill(e.Enemy.level, e.Enemy.pos, e.Time)
+	})
+	Subscribe(bus, func(e GrenadeExploded) {
+		scoring.AddMultiKill(e.Kills, e.Pos)
+	})
+	Subscribe(bus, func(e DamageTaken) {
+		scoring.PlayerDamaged()
+	})
+	Subscribe(bus, func(e LevelCompleted) {
+		center := rl.NewVector2(float32(rl.GetScreenWidth())/2, float32(rl.GetScreenHeight())/2+60)
+		scoring.CompleteLevel(e.Level, e.EnemyCount, center)
+	})
+	Subscribe(bus, func(e LevelStarted) {
+		scoring.StartLevel()
+	})
+}
+
+// Start a new run
+func (s *ScoreSystem) Reset() {
+	s.score = 0
+	s.multiplier = 1
+	s.chain = 0
+	s.lastKillTime = 0
+	s.popups = s.popups[:0]
+	s.StartLevel()
+}
+
+// Start timing a new level
+func (s *ScoreSystem) StartLevel() {
+	s.levelTime = 0
+	s.levelDamageTaken = false
+}
+
+// Score a kill and extend the chain
+func (s *ScoreSystem) AddKill(enemyLevel int, pos rl.Vector2, currentTime float64) {
+	if currentTime-s.lastKillTime <= comboWindow {
+		s.chain++
+		s.multiplier += comboStep
+		if s.multiplier > maxMultiplier {
+			s.multiplier = maxMultiplier
+		}
+	} else {
+		s.chain = 1
+	}
+	s.lastKillTime = currentTime
+
+	points := int(float32(pointsPerKill*enemyLevel) * s.multiplier)
+	s.score += points
+	s.addPopup(fmt.Sprintf("+%d", points), pos, rl.White)
+}
+
+// Bonus for killing several enemies with one grenade
+func (s *ScoreSystem) AddMultiKill(kills int, pos rl.Vector2) {
+	if kills < 2 {
+		return
+	}
+
+	points := multiKillBonus * (kills - 1)
+	s.score += points
+	s.addPopup(fmt.Sprintf("MULTI KILL x%d +%d", kills, points), rl.Vector2Add(pos, rl.NewVector2(0, -30)), rl.Orange)
+}
+
+// Remember the player got hurt this level
+func (s *ScoreSystem) PlayerDamaged() {
+	s.levelDamageTaken = true
+}
+
+// Award the level clear bonuses
+func (s *ScoreSystem) CompleteLevel(level, enemyCount int, pos rl.Vector2) {
+	offset := float32(0)
+
+	if !s.levelDamageTaken {
+		points := noDamageBonusPerLevel * level
+		s.score += points
+		s.addPopup(fmt.Sprintf("NO DAMAGE +%d", points), pos, rl.Green)
+		offset += 35
+	}
+
+	par := fastClearParPerEnemy * float64(enemyCount)
+	if s.levelTime < par {
+		points := fastClearBonusPerSec * int(par-s.levelTime)
+		if points > 0 {
+			s.score += points
+			s.addPopup(fmt.Sprintf("FAST CLEAR +%d", points), rl.Vector2Add(pos, rl.NewVector2(0, offset)), rl.SkyBlue)
+		}
+	}
+}
+
+func (s *ScoreSystem) addPopup(text string, pos rl.Vector2, color rl.Color) {
+	s.popups = append(s.popups, ScorePopup{text: text, pos: pos, color: color, life: popupDuration})
+}
+
+// Time the level, decay the multiplier once the chain is broken and move the pop-ups.
+// Only called while the game isn't paused, so pauses don't count against a fast clear
+func (s *ScoreSystem) Update(dt float64, currentTime float64) {
+	s.levelTime += dt
+
+	if currentTime-s.lastKillTime > comboWindow {
+		s.chain = 0
+		s.multiplier -= multiplierDecay * float32(dt)
+		if s.multiplier < 1 {
+			s.multiplier = 1
+		}
+	}
+
+	alive := s.popups[:0]
+	for _, popup := range s.popups {
+		popup.life -= float32(dt)
+		popup.pos.Y -= popupRiseSpeed * float32(dt)
+		if popup.life > 0 {
+			alive = append(alive, popup)
+		}
+	}
+	s.popups = alive
+}
+
+// Draw the pop-ups
+func (s *ScoreSystem) Render() {
+	for _, popup := range s.popups {
+		width := rl.MeasureText(popup.text, 20)
+		rl.DrawText(popup.text, int32(popup.pos.X)-width/2, int32(popup.pos.Y), 20, rl.ColorAlpha(popup.color, popup.life/popupDuration))
+	}
+}
+
+// Draw the score and the multiplier at the top center of the screen
+func (s *ScoreSystem) RenderHUD(screenWidth int32, currentTime float64) {
+	scoreText := fmt.Sprintf("Score: %d", s.score)
+	scoreWidth := rl.MeasureText(scoreText, 30)
+	rl.DrawText(scoreText, screenWidth/2-scoreWidth/2, 10, 30, rl.White)
+
+	if s.multiplier <= 1 {
+		return
+	}
+
+	multiplierText := fmt.Sprintf("x%.2f", s.multiplier)
+	multiplierWidth := rl.MeasureText(multiplierText, 25)
+	rl.DrawText(multiplierText, screenWidth/2-multiplierWidth/2, 45, 25, rl.Orange)
+
+	// Time left to extend the chain
+	remaining := 1 - float32((currentTime-s.lastKillTime)/comboWindow)
+	if remaining > 0 {
+		barWidth := float32(120)
+		rl.DrawRectangle(screenWidth/2-int32(barWidth/2), 75, int32(barWidth), 4, rl.DarkGray)
+		rl.DrawRectangle(screenWidth/2-int32(barWidth/2), 75, int32(barWidth*remaining), 4, rl.Orange)
+	}
+}
+
+// The score lives for the whole game
+func (s *ScoreSystem) Destroyed() bool {
+	return false
+}
+
+func (s *ScoreSystem) Layer() RenderLayer {
+	return LayerHUD
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const SETTINGS_FILE = "settings.json"
+
+var volumeStep float32 = 0.1 // Volume change per key press
+
+// Settings are the player's options as stored on disk, a missing key keeps its default
+type Settings struct {
+	MasterVolume *float32           `json:"masterVolume,omitempty"`
+	MusicVolume  *float32           `json:"musicVolume,omitempty"`
+	SoundVolumes map[string]float32 `json:"soundVolumes,omitempty"` // Volume of every sound category by name
+}
+
+// Rows of the volume settings, the sound categories follow master and music
+const (
+	volumeRowMaster = iota
+	volumeRowMusic
+	volumeRowCategories
+	volumeRowCount = volumeRowCategories + int(categoryCount)
+)
+
+// Load the saved settings and apply them, without a file the defaults stay.
+// Call after InitAudio and InitMusic
+func LoadSettings() {
+	data, err := os.ReadFile(saveFilePath(SETTINGS_FILE))
+	if err != nil {
+		return
+	}
+	var settings Settings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		rl.TraceLog(rl.LogWarning, "Settings are damaged, using the defaults: %s", err.Error())
+		return
+	}
+
+	if settings.MasterVolume != nil {
+		SetMasterVolume(*settings.MasterVolume)
+	}
+	if settings.MusicVolume != nil {
+		SetMusicVolume(*settings.MusicVolume)
+	}
+	for category, name := range categoryNames {
+		if volume, ok := settings.SoundVolumes[name]; ok {
+			SetCategoryVolume(SoundCategory(category), volume)
+		}
+	}
+}
+
+// Write the current settings to disk
+func SaveSettings() {
+	settings := Settings{
+		MasterVolume: &masterVolume,
+		MusicVolume:  &musicVolume,
+		SoundVolumes: make(map[string]float32, categoryCount),
+	}
+	for category, name := range categoryNames {
+		settings.SoundVolumes[name] = categoryVolume[category]
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "Failed to save settings: %s", err.Error())
+		return
+	}
+
+	if err := writeFileAtomic(saveFilePath(SETTINGS_FILE), data); err != nil {
+		rl.TraceLog(rl.LogWarning, "Failed to save settings: %s", err.Error())
+	}
+}
+
+func volumeRowLabel(row int) string {
+	switch row {
+	case volumeRowMaster:
+		return "Master"
+	case volumeRowMusic:
+		return "Music"
+	}
+	return categoryLabels[row-volumeRowCategories]
+}
+
+func volumeAt(row int) float32 {
+	switch row {
+	case volumeRowMaster:
+		return masterVolume
+	case volumeRowMusic:
+		return musicVolume
+	}
+	return categoryVolume[row-volumeRowCategories]
+}
+
+func setVolumeAt(row int, volume float32) {
+	switch row {
+	case volumeRowMaster:
+		SetMasterVolume(volume)
+	case volumeRowMusic:
+		SetMusicVolume(volume)
+	default:
+		SetCategoryVolume(SoundCategory(row-volumeRowCategories), volume)
+	}
+}
+
+// Pick a volume with UP and DOWN and change it with LEFT and RIGHT, changes are saved right away
+func UpdateVolumeSettings(selected *int) {
+	if rl.IsKeyPressed(rl.KeyUp) {
+		*selected = (*selected + volumeRowCount - 1) % volumeRowCount
+	}
+	if rl.IsKeyPressed(rl.KeyDown) {
+		*selected = (*selected + 1) % volumeRowCount
+	}
+
+	change := float32(0)
+	if rl.IsKeyPressed(rl.KeyLeft) {
+		change -= volumeStep
+	}
+	if rl.IsKeyPressed(rl.KeyRight) {
+		change += volumeStep
+	}
+	if change == 0 {
+		return
+	}
+
+	// Snap to whole steps so repeated presses don't drift
+	volume := volumeAt(*selected) + change
+	volume = float32(math.Round(float64(volume/volumeStep))) * volumeStep
+	setVolumeAt(*selected, volume)
+	SaveSettings()
+}
+
+// Draw the volume of every row as a bar, the selected row highlighted
+func DrawVolumeSettings(selected int, centerX, y int32) {
+	title := "VOLUME"
+	titleWidth := rl.MeasureText(title, 30)
+	rl.DrawText(title, centerX-titleWidth/2, y, 30, rl.Gold)
+
+	rowSpacing := int32(30)
+	barWidth := int32(200)
+	left := centerX - 180
+
+	for row := 0; row < volumeRowCount; row++ {
+		rowY := y + 50 + int32(row)*rowSpacing
+		volume := volumeAt(row)
+
+		color := rl.Gray
+		if row == selected {
+			color = rl.White
+		}
+
+		rl.DrawText(volumeRowLabel(row), left, rowY, 20, color)
+		rl.DrawRectangle(left+120, rowY+4, barWidth, 12, rl.DarkGray)
+		rl.DrawRectangle(left+120, rowY+4, int32(float32(barWidth)*volume), 12, color)
+		rl.DrawText(fmt.Sprintf("%d%%", int(math.Round(float64(volume*100)))), left+120+barWidth+15, rowY, 20, color)
+	}
+
+	hint := "UP/DOWN: select   LEFT/RIGHT: adjust"
+	hintWidth := rl.MeasureText(hint, 20)
+	rl.DrawText(hint, centerX-hintWidth/2, y+60+int32(volumeRowCount)*rowSpacing, 20, rl.Gray)
+}
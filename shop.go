@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const SHOP_FILE = "assets/shop.json"
+
+// Kinds of things the shop sells
+const (
+	ShopWeapon  = "weapon"
+	ShopAmmo    = "ammo"
+	ShopGrenade = "grenade"
+	ShopHealth  = "health"
+	ShopArmor   = "armor"
+)
+
+var (
+	shopWidth     int32 = 620
+	shopRowHeight int32 = 34
+)
+
+// ShopItemDef is one item as read from the data file
+type ShopItemDef struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Kind       string  `json:"kind"`
+	Target     string  `json:"target,omitempty"` // Weapon, ammo type or grenade kind sold
+	Amount     int     `json:"amount"`
+	Price      int     `json:"price"`      // Price on the first level
+	PriceScale float64 `json:"priceScale"` // Price increase per level as a fraction of the first price
+	Stock      int     `json:"stock"`      // How many can be bought per visit, 0 for no limit
+
+	// Resolved from Target when loading
+	weapon      weapon
+	ammoType    AmmoType
+	grenadeKind GrenadeKind
+}
+
+// Everything the shop can sell
+var shopDefs []ShopItemDef
+
+// Load the shop items, items with an unknown target are left out
+func InitShop() {
+	data, err := os.ReadFile(SHOP_FILE)
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "Failed to load the shop from %s! The shop is disabled.", SHOP_FILE)
+		return
+	}
+	var defs []ShopItemDef
+	if err := json.Unmarshal(data, &defs); err != nil {
+		rl.TraceLog(rl.LogWarning, "Failed to parse %s: %s", SHOP_FILE, err.Error())
+		return
+	}
+
+	for _, def := range defs {
+		if def.resolve() {
+			shopDefs = append(shopDefs, def)
+		} else {
+			rl.TraceLog(rl.LogWarning, "Unknown %s %s in shop item %s", def.Kind, def.Target, def.ID)
+		}
+	}
+}
+
+// Find what Target names, returns false if it doesn't exist
+func (def *ShopItemDef) resolve() bool {
+	switch def.Kind {
+	case ShopWeapon:
+		for _, w := range lootWeapons {
+			if w.weaponName == def.Target {
+				def.weapon = w
+				return true
+			}
+		}
+	case ShopAmmo:
+		for t, ammo := range ammoDefs {
+			if ammo.name == def.Target {
+				def.ammoType = AmmoType(t)
+				return true
+			}
+		}
+	case ShopGrenade:
+		for kind, grenade := range grenadeDefs {
+			if grenade.name == def.Target {
+				def.grenadeKind = GrenadeKind(kind)
+				return true
+			}
+		}
+	case ShopHealth, ShopArmor:
+		return true
+	}
+	return false
+}
+
+// Price of the item on a level
+func (def *ShopItemDef) PriceAt(level int) int {
+	return int(math.Round(float64(def.Price) * (1 + def.PriceScale*float64(level-1))))
+}
+
+// Whether buying the item would give the player anything
+func (def *ShopItemDef) useful(p *player) bool {
+	switch def.Kind {
+	case ShopAmmo:
+		return p.ammo[def.ammoType] < ammoDefs[def.ammoType].carryCap
+	case ShopHealth:
+		return p.CurrentHp < p.MaxHp()
+	case ShopArmor:
+		return p.armor < maxArmor
+	}
+	return true
+}
+
+// Shop is open between levels, after the perk draft
+type Shop struct {
+	open  bool
+	level int            // Level the prices are for
+	stock map[string]int // Items left this visit
+}
+
+// Open the shop with prices for the level and fresh stock
+func (s *Shop) Open(level int) {
+	if len(shopDefs) == 0 {
+		return
+	}
+	s.open = true
+	s.level = level
+	s.stock = make(map[string]int)
+	for _, def := range shopDefs {
+		s.stock[def.ID] = def.Stock
+	}
+}
+
+// Whether the player is still shopping
+func (s *Shop) Active() bool {
+	return s.open
+}
+
+func (s *Shop) Close() {
+	s.open = false
+}
+
+// Whether the item can be bought right now
+func (s *Shop) canBuy(def *ShopItemDef, p *player) bool {
+	if def.Stock > 0 && s.stock[def.ID] <= 0 {
+		return false
+	}
+	return p.coins >= def.PriceAt(s.level) && def.useful(p)
+}
+
+// Top left corner of the shop panel
+func (s *Shop) panelPos(screenWidth, screenHeight int32) (int32, int32) {
+	height := int32(len(shopDefs))*shopRowHeight + 110
+	return screenWidth/2 - shopWidth/2, screenHeight/2 - height/2
+}
+
+// Where the row of an item is drawn
+func (s *Shop) rowRect(i int, screenWidth, screenHeight int32) rl.Rectangle {
+	x, y := s.panelPos(screenWidth, screenHeight)
+	return rl.NewRectangle(float32(x+10), float32(y+60+int32(i)*shopRowHeight), float32(shopWidth-20), float32(shopRowHeight-4))
+}
+
+// Buy items by clicking them and leave with ENTER. A weapon bought with a full inventory
+// swaps out the one in hand, it's returned so it can be dropped
+func (s *Shop) Update(p *player, screenWidth, screenHeight int32, currentTime float64) (weapon, int, bool) {
+	if rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeySpace) {
+		s.Close()
+		return weapon{}, 0, false
+	}
+
+	if !rl.IsMouseButtonPressed(rl.MouseLeftButton) {
+		return weapon{}, 0, false
+	}
+
+	for i := range shopDefs {
+		def := &shopDefs[i]
+		if !rl.CheckCollisionPointRec(rl.GetMousePosition(), s.rowRect(i, screenWidth, screenHeight)) {
+			continue
+		}
+		if !s.canBuy(def, p) {
+			PlaySFX(SoundEmptyClick)
+			return weapon{}, 0, false
+		}
+
+		p.coins -= def.PriceAt(s.level)
+		s.stock[def.ID]--
+		PlaySFX(SoundPickup)
+
+		switch def.Kind {
+		case ShopWeapon:
+			Publish(events, WeaponEquipped{Name: def.weapon.weaponName, Time: currentTime})
+			return p.PickUpWeapon(def.weapon, -1, currentTime)
+		case ShopAmmo:
+			p.AddAmmo(def.ammoType, def.Amount)
+		case ShopGrenade:
+			p.AddGrenades(def.grenadeKind, def.Amount)
+		case ShopHealth:
+			p.Heal(def.Amount)
+		case ShopArmor:
+			p.AddArmor(def.Amount)
+		}
+		break
+	}
+	return weapon{}, 0, false
+}
+
+// Draw the shop panel, items that can't be bought are grayed out
+func (s *Shop) Render(p *player, screenWidth, screenHeight int32) {
+	x, y := s.panelPos(screenWidth, screenHeight)
+	height := int32(len(shopDefs))*shopRowHeight + 110
+
+	rl.DrawRectangle(x, y, shopWidth, height, rl.ColorAlpha(rl.Black, 0.9))
+	rl.DrawRectangleLines(x, y, shopWidth, height, rl.Gold)
+
+	rl.DrawText("SHOP", x+15, y+15, 30, rl.Gold)
+	coinsText := fmt.Sprintf("Coins: %d", p.coins)
+	coinsWidth := rl.MeasureText(coinsText, 25)
+	rl.DrawText(coinsText, x+shopWidth-coinsWidth-15, y+18, 25, rl.Gold)
+
+	for i := range shopDefs {
+		def := &shopDefs[i]
+		rect := s.rowRect(i, screenWidth, screenHeight)
+		rowX, rowY := int32(rect.X), int32(rect.Y)
+
+		if rl.CheckCollisionPointRec(rl.GetMousePosition(), rect) {
+			rl.DrawRectangleRec(rect, rl.ColorAlpha(rl.DarkGray, 0.6))
+		}
+
+		color := rl.White
+		if !s.canBuy(def, p) {
+			color = rl.Gray
+		}
+
+		name := def.Name
+		if def.Kind != ShopWeapon {
+			name = fmt.Sprintf("%s +%d", def.Name, def.Amount)
+		}
+		rl.DrawText(name, rowX+8, rowY+6, 20, color)
+
+		if def.Stock > 0 {
+			rl.DrawText(fmt.Sprintf("%d left", s.stock[def.ID]), rowX+330, rowY+6, 20, rl.Gray)
+		}
+
+		priceText := fmt.Sprintf("%d", def.PriceAt(s.level))
+		priceWidth := rl.MeasureText(priceText, 20)
+		rl.DrawText(priceText, rowX+int32(rect.Width)-priceWidth-8, rowY+6, 20, rl.Gold)
+	}
+
+	hintText := "Click to buy   ENTER: next level"
+	hintWidth := rl.MeasureText(hintText, 18)
+	rl.DrawText(hintText, x+shopWidth/2-hintWidth/2, y+height-32, 18, rl.Gray)
+}
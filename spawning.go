@@ -0,0 +1,153 @@
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// What a spawn zone is used for
+const (
+	SpawnEnemies = "enemies"
+	SpawnLoot    = "loot"
+)
+
+var (
+	spawnAttempts     int     = 30  // Points tried before falling back
+	spawnEdgeMargin   float32 = 100 // Zombies don't come out this close to the edges of the screen
+	enemySpawnMinDist float32 = 200 // Zombies come out at least this far from the player
+	enemySpawnMaxDist float32 = 400 // and no farther than this
+	lootSpawnMinDist  float32 = 150 // Pickups don't appear right under the player
+)
+
+// SpawnZone is an area of the map where things of a kind can appear
+type SpawnZone struct {
+	kind string
+	rect rl.Rectangle
+}
+
+// SpawnRule is what makes a point good enough to spawn on
+type SpawnRule struct {
+	Radius        float32               // Room kept from blocks and the world edges
+	MinPlayerDist float32               // Closest the point can be to the player
+	MaxPlayerDist float32               // Farthest the point can be from the player, 0 for no limit
+	Occupied      func(rl.Vector2) bool // Extra check for things in the way, can be nil
+}
+
+// Spawner picks spawn points inside the zones of the map, away from blocks and the player
+type Spawner struct {
+	bounds rl.Rectangle // Nothing is placed outside the world
+	zones  []SpawnZone
+	blocks []*Block
+}
+
+func NewSpawner(bounds rl.Rectangle, blocks []*Block) Spawner {
+	return Spawner{
+		bounds: bounds,
+		blocks: blocks,
+	}
+}
+
+// Let things of a kind spawn inside rect
+func (s *Spawner) AddZone(kind string, rect rl.Rectangle) {
+	s.zones = append(s.zones, SpawnZone{kind: kind, rect: rect})
+}
+
+// Whether a circle at pos is inside the world and clear of every block
+func (s *Spawner) clear(pos rl.Vector2, radius float32) bool {
+	if pos.X < s.bounds.X+radius || pos.X > s.bounds.X+s.bounds.Width-radius ||
+		pos.Y < s.bounds.Y+radius || pos.Y > s.bounds.Y+s.bounds.Height-radius {
+		return false
+	}
+	for _, block := range s.blocks {
+		if rl.CheckCollisionCircleRec(pos, radius, block.GetRectangle()) {
+			return false
+		}
+	}
+	return true
+}
+
+// Room around a square pickup so no corner of it overlaps a block
+func pickupClearance(size float32) float32 {
+	return size * 0.71
+}
+
+// Move a circle out of the blocks and back inside the world
+func (s *Spawner) Nudge(pos rl.Vector2, radius float32) rl.Vector2 {
+	pos, _ = resolveCircleBlocks(pos, radius, s.blocks)
+	pos.X = rl.Clamp(pos.X, s.bounds.X+radius, s.bounds.X+s.bounds.Width-radius)
+	pos.Y = rl.Clamp(pos.Y, s.bounds.Y+radius, s.bounds.Y+s.bounds.Height-radius)
+	return pos
+}
+
+// Part of each zone of a kind the rule can be met in, a player distance limit
+// keeps the search close to the player
+func (s *Spawner) searchAreas(kind string, rule SpawnRule, playerPos rl.Vector2) []rl.Rectangle {
+	var areas []rl.Rectangle
+	for _, zone := range s.zones {
+		if zone.kind != kind {
+			continue
+		}
+		area := zone.rect
+		if rule.MaxPlayerDist > 0 {
+			around := rl.NewRectangle(playerPos.X-rule.MaxPlayerDist, playerPos.Y-rule.MaxPlayerDist, rule.MaxPlayerDist*2, rule.MaxPlayerDist*2)
+			area = rl.GetCollisionRec(area, around)
+		}
+		if area.Width > 0 && area.Height > 0 {
+			areas = append(areas, area)
+		}
+	}
+	return areas
+}
+
+// Random point in one of the areas, bigger areas are picked more often
+func randomPointInAreas(areas []rl.Rectangle) rl.Vector2 {
+	total := float32(0)
+	for _, area := range areas {
+		total += area.Width * area.Height
+	}
+
+	roll := total * float32(RandomValue(0, 9999)) / 10000
+	area := areas[len(areas)-1]
+	for _, a := range areas {
+		if roll < a.Width*a.Height {
+			area = a
+			break
+		}
+		roll -= a.Width * a.Height
+	}
+
+	return rl.NewVector2(
+		area.X+area.Width*float32(RandomValue(0, 10000))/10000,
+		area.Y+area.Height*float32(RandomValue(0, 10000))/10000,
+	)
+}
+
+// Pick a point in the zones of a kind that meets the rule. After spawnAttempts tries it
+// falls back to the tried point farthest from the player, moved clear of the blocks, and
+// returns false. Without any zone of the kind the whole world is searched
+func (s *Spawner) Place(kind string, rule SpawnRule, playerPos rl.Vector2) (rl.Vector2, bool) {
+	areas := s.searchAreas(kind, rule, playerPos)
+	if len(areas) == 0 {
+		areas = []rl.Rectangle{s.bounds}
+	}
+
+	var fallback rl.Vector2
+	fallbackDist := float32(-1)
+
+	for attempt := 0; attempt < spawnAttempts; attempt++ {
+		pos := randomPointInAreas(areas)
+		dist := rl.Vector2Distance(pos, playerPos)
+
+		if s.clear(pos, rule.Radius) && dist >= rule.MinPlayerDist &&
+			(rule.MaxPlayerDist <= 0 || dist <= rule.MaxPlayerDist) &&
+			(rule.Occupied == nil || !rule.Occupied(pos)) {
+			return pos, true
+		}
+
+		if dist > fallbackDist {
+			fallback, fallbackDist = pos, dist
+		}
+	}
+
+	rl.TraceLog(rl.LogDebug, "No free %s spawn point after %d attempts, using a fallback", kind, spawnAttempts)
+	return s.Nudge(fallback, rule.Radius), false
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+var (
+	maxStamina        float32 = 100
+	sprintMultiplier  float32 = 1.6 // Speed while sprinting
+	sprintDrain       float32 = 35  // Stamina used per second of sprinting
+	staminaRegen      float32 = 25  // Stamina recovered per second
+	staminaRegenDelay float64 = 0.8 // Seconds after using stamina before it recovers
+
+	dodgeCost     float32 = 30   // Stamina used by a dodge roll
+	dodgeSpeed    float32 = 900  // Speed of the roll
+	dodgeTime     float64 = 0.22 // How long the roll lasts
+	dodgeIFrames  float64 = 0.3  // Seconds without damage from the start of the roll
+	dodgeCooldown float64 = 0.8  // Seconds between rolls
+)
+
+// Keys for the mobility moves
+var (
+	sprintKey int32 = rl.KeyLeftShift
+	dodgeKey  int32 = rl.KeySpace
+)
+
+// Use stamina, returns false without using any if there isn't enough
+func (p *player) useStamina(amount float32, currentTime float64) bool {
+	if p.stamina < amount {
+		return false
+	}
+	p.stamina -= amount
+	p.lastStaminaUse = currentTime
+	return true
+}
+
+// Speed multiplier for this frame, sprinting drains stamina while the player is moving
+func (p *player) SprintMultiplier(moving bool, dt float64, currentTime float64) float32 {
+	if moving && rl.IsKeyDown(sprintKey) && p.stamina > 0 {
+		p.stamina -= sprintDrain * float32(dt)
+		if p.stamina < 0 {
+			p.stamina = 0
+		}
+		p.lastStaminaUse = currentTime
+		return sprintMultiplier
+	}
+	return 1
+}
+
+// Roll along dir, or towards the cursor when standing still
+func (p *player) StartDodge(dir rl.Vector2, currentTime float64) bool {
+	if currentTime < p.nextDodge || p.Dodging(currentTime) {
+		return false
+	}
+	if !p.useStamina(dodgeCost, currentTime) {
+		return false
+	}
+
+	if dir.X == 0 && dir.Y == 0 {
+		dir = p.lookAt
+	}
+	if dir.X == 0 && dir.Y == 0 {
+		dir = rl.NewVector2(1, 0)
+	}
+
+	p.dodgeDir = rl.Vector2Normalize(dir)
+	p.dodgeUntil = currentTime + dodgeTime
+	p.nextDodge = currentTime + dodgeCooldown
+
+	// Rolling through a hit doesn't hurt
+	if currentTime+dodgeIFrames > p.invulnerableUntil {
+		p.invulnerableUntil = currentTime + dodgeIFrames
+	}
+	return true
+}
+
+// Whether the player is in the middle of a roll
+func (p *player) Dodging(currentTime float64) bool {
+	return currentTime < p.dodgeUntil
+}
+
+// Recover stamina a little while after it was last used
+func (p *player) UpdateStamina(dt float64, currentTime float64) {
+	if currentTime-p.lastStaminaUse < staminaRegenDelay {
+		return
+	}
+	p.stamina += staminaRegen * float32(dt)
+	if p.stamina > maxStamina {
+		p.stamina = maxStamina
+	}
+}
+
+// Thin bar under the health bar, only shown while stamina isn't full
+func drawStaminaBar(p *player, yPosition float32) {
+	if p.stamina >= maxStamina {
+		return
+	}
+
+	barWidth := playerSize * 3
+	rl.DrawRectangle(int32(p.Pos.X-barWidth/2), int32(yPosition), int32(barWidth), 3, rl.DarkGray)
+	rl.DrawRectangle(int32(p.Pos.X-barWidth/2), int32(yPosition), int32(barWidth*p.stamina/maxStamina), 3, rl.Yellow)
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Name used in the per-weapon breakdown for grenade kills and damage
+const GRENADE_WEAPON_NAME = "Grenade"
+
+// Tabs of the game over screen
+const (
+	TabSummary = iota
+	TabWeapons
+	TabLevels
+	TabHighScores
+	tabCount
+)
+
+var statsTabNames = [tabCount]string{"SUMMARY", "WEAPONS", "LEVELS", "HIGH SCORES"}
+
+// WeaponStats is the breakdown of one weapon over a run
+type WeaponStats struct {
+	Name     string  `json:"name"`
+	Shots    int     `json:"shots"`
+	Hits     int     `json:"hits"`
+	Kills    int     `json:"kills"`
+	Damage   float32 `json:"damage"`
+	Reloads  int     `json:"reloads"`
+	TimeUsed float64 `json:"timeUsed"` // Seconds the weapon was held
+}
+
+// Shots that didn't hit anything
+func (ws *WeaponStats) Misses() int {
+	return ws.Shots - ws.Hits
+}
+
+// Hits per shot from 0 to 1
+func (ws *WeaponStats) Accuracy() float32 {
+	if ws.Shots == 0 {
+		return 0
+	}
+	return float32(ws.Hits) / float32(ws.Shots)
+}
+
+// LevelStats is the breakdown of one level over a run
+type LevelStats struct {
+	Level       int     `json:"level"`
+	Kills       int     `json:"kills"`
+	DamageTaken float32 `json:"damageTaken"`
+}
+
+// Breakdown of a weapon, created the first time it's used
+func (gs *GameStats) weapon(name string) *WeaponStats {
+	ws, ok := gs.weapons[name]
+	if !ok {
+		ws = &WeaponStats{Name: name}
+		gs.weapons[name] = ws
+		gs.weaponOrder = append(gs.weaponOrder, name)
+	}
+	return ws
+}
+
+// Breakdown of a level, created the first time something happens in it
+func (gs *GameStats) level(level int) *LevelStats {
+	ls, ok := gs.levels[level]
+	if !ok {
+		ls = &LevelStats{Level: level}
+		gs.levels[level] = ls
+	}
+	return ls
+}
+
+// Count shots fired with a weapon
+func (gs *GameStats) RecordShots(weaponName string, count int) {
+	gs.shotsFired += count
+	gs.weapon(weaponName).Shots += count
+}
+
+// Count a projectile hitting an enemy
+func (gs *GameStats) RecordHit(weaponName string, damage float32) {
+	gs.hits++
+	gs.damageDealt += damage
+	ws := gs.weapon(weaponName)
+	ws.Hits++
+	ws.Damage += damage
+}
+
+// Count damage that doesn't come from a projectile (explosions)
+func (gs *GameStats) RecordDamage(weaponName string, damage float32) {
+	gs.damageDealt += damage
+	gs.weapon(weaponName).Damage += damage
+}
+
+// Count a kill
+func (gs *GameStats) RecordKill(weaponName string, level int) {
+	gs.enemiesKilled++
+	gs.weapon(weaponName).Kills++
+	gs.level(level).Kills++
+}
+
+// Count a reload
+func (gs *GameStats) RecordReload(weaponName string) {
+	gs.reloads++
+	gs.weapon(weaponName).Reloads++
+}
+
+// Count damage the player took
+func (gs *GameStats) RecordDamageTaken(level int, damage float32) {
+	gs.level(level).DamageTaken += damage
+}
+
+// Count a collected pickup by kind ("weapon", "ammo", "grenade")
+func (gs *GameStats) RecordPickup(kind string) {
+	gs.pickups[kind]++
+}
+
+// Add the time the current weapon was held this frame
+func (gs *GameStats) RecordWeaponTime(weaponName string, dt float64) {
+	gs.weapon(weaponName).TimeUsed += dt
+}
+
+// Hits per shot over every weapon
+func (gs *GameStats) Accuracy() float32 {
+	if gs.shotsFired == 0 {
+		return 0
+	}
+	return float32(gs.hits) / float32(gs.shotsFired)
+}
+
+// Total pickups collected
+func (gs *GameStats) PickupsCollected() int {
+	total := 0
+	for _, count := range gs.pickups {
+		total += count
+	}
+	return total
+}
+
+// Breakdown per level in level order
+func (gs *GameStats) Levels() []LevelStats {
+	var levels []LevelStats
+	for level := 1; level <= gs.levelReached; level++ {
+		if ls, ok := gs.levels[level]; ok {
+			levels = append(levels, *ls)
+		} else {
+			levels = append(levels, LevelStats{Level: level})
+		}
+	}
+	return levels
+}
+
+// Breakdown per weapon in the order they were first used
+func (gs *GameStats) Weapons() []WeaponStats {
+	weapons := make([]WeaponStats, 0, len(gs.weaponOrder))
+	for _, name := range gs.weaponOrder {
+		weapons = append(weapons, *gs.weapons[name])
+	}
+	return weapons
+}
+
+// statsReport is the JSON layout of an exported run
+type statsReport struct {
+	Date        string         `json:"date"`
+	Seed        int64          `json:"seed"`
+	Score       int            `json:"score"`
+	Level       int            `json:"level"`
+	Kills       int            `json:"kills"`
+	Shots       int            `json:"shots"`
+	Hits        int            `json:"hits"`
+	Misses      int            `json:"misses"`
+	Accuracy    float32        `json:"accuracy"`
+	DamageDealt float32        `json:"damageDealt"`
+	TimeAlive   float64        `json:"timeAlive"`
+	Grenades    int            `json:"grenades"`
+	Reloads     int            `json:"reloads"`
+	Pickups     map[string]int `json:"pickups"`
+	Weapons     []WeaponStats  `json:"weapons"`
+	Levels      []LevelStats   `json:"levels"`
+}
+
+// Write the stats of the run to a JSON file in the save directory, returns its path
+func ExportStats(gs *GameStats, score int) (string, error) {
+	now := time.Now()
+	report := statsReport{
+		Date:        now.Format(time.RFC3339),
+		Seed:        runSeed,
+		Score:       score,
+		Level:       gs.levelReached,
+		Kills:       gs.enemiesKilled,
+		Shots:       gs.shotsFired,
+		Hits:        gs.hits,
+		Misses:      gs.shotsFired - gs.hits,
+		Accuracy:    gs.Accuracy(),
+		DamageDealt: gs.damageDealt,
+		TimeAlive:   gs.timeAlive,
+		Grenades:    gs.grenadesThrown,
+		Reloads:     gs.reloads,
+		Pickups:     gs.pickups,
+		Weapons:     gs.Weapons(),
+		Levels:      gs.Levels(),
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := saveFilePath(fmt.Sprintf("stats-%s.json", now.Format("20060102-150405")))
+	return path, os.WriteFile(path, data, 0644)
+}
+
+// Switch tabs with TAB or the arrow keys
+func UpdateStatsTab(tab int) int {
+	if rl.IsKeyPressed(rl.KeyTab) || rl.IsKeyPressed(rl.KeyRight) {
+		return (tab + 1) % tabCount
+	}
+	if rl.IsKeyPressed(rl.KeyLeft) {
+		return (tab + tabCount - 1) % tabCount
+	}
+	return tab
+}
+
+// Draw the tab titles centered at y, the selected one highlighted
+func DrawStatsTabs(tab int, centerX, y int32) {
+	spacing := int32(40)
+
+	totalWidth := int32(0)
+	for _, name := range statsTabNames {
+		totalWidth += rl.MeasureText(name, 25) + spacing
+	}
+	x := centerX - (totalWidth-spacing)/2
+
+	for i, name := range statsTabNames {
+		color := rl.Gray
+		if i == tab {
+			color = rl.Gold
+		}
+		width := rl.MeasureText(name, 25)
+		rl.DrawText(name, x, y, 25, color)
+		if i == tab {
+			rl.DrawRectangle(x, y+30, width, 3, rl.Gold)
+		}
+		x += width + spacing
+	}
+}
+
+// Draw the summary of the run centered at centerX
+func DrawStatsSummary(gs *GameStats, score int, centerX, y int32) {
+	minutes := int(gs.timeAlive) / 60
+	seconds := int(gs.timeAlive) % 60
+
+	lines := []string{
+		fmt.Sprintf("Score: %d", score),
+		fmt.Sprintf("Level Reached: %d", gs.levelReached),
+		fmt.Sprintf("Enemies Killed: %d", gs.enemiesKilled),
+		fmt.Sprintf("Shots Fired: %d", gs.shotsFired),
+		fmt.Sprintf("Hits / Misses: %d / %d", gs.hits, gs.shotsFired-gs.hits),
+		fmt.Sprintf("Accuracy: %.1f%%", gs.Accuracy()*100),
+		fmt.Sprintf("Damage Dealt: %.0f", gs.damageDealt),
+		fmt.Sprintf("Time Survived: %d:%02d", minutes, seconds),
+		fmt.Sprintf("Grenades Thrown: %d", gs.grenadesThrown),
+		fmt.Sprintf("Reloads: %d", gs.reloads),
+		fmt.Sprintf("Pickups Collected: %d", gs.PickupsCollected()),
+	}
+
+	spacing := int32(35)
+	for i, text := range lines {
+		textWidth := rl.MeasureText(text, 30)
+		rl.DrawText(text, centerX-textWidth/2, y+int32(i)*spacing, 30, rl.Gold)
+	}
+}
+
+// Draw the per-weapon breakdown centered at centerX
+func DrawWeaponStats(gs *GameStats, centerX, y int32) {
+	columns := []int32{0, 160, 250, 340, 440, 520, 630, 730}
+	left := centerX - 420
+	drawTableRow(left, columns, y, rl.Gray, "WEAPON", "SHOTS", "HITS", "ACCURACY", "KILLS", "DAMAGE", "RELOADS", "TIME")
+
+	for i, ws := range gs.Weapons() {
+		// Grenades aren't aimed, their accuracy means nothing
+		accuracy := fmt.Sprintf("%.1f%%", ws.Accuracy()*100)
+		if ws.Name == GRENADE_WEAPON_NAME {
+			accuracy = "-"
+		}
+
+		drawTableRow(left, columns, y+int32(i+1)*30, rl.White,
+			ws.Name,
+			fmt.Sprintf("%d", ws.Shots),
+			fmt.Sprintf("%d", ws.Hits),
+			accuracy,
+			fmt.Sprintf("%d", ws.Kills),
+			fmt.Sprintf("%.0f", ws.Damage),
+			fmt.Sprintf("%d", ws.Reloads),
+			fmt.Sprintf("%d:%02d", int(ws.TimeUsed)/60, int(ws.TimeUsed)%60),
+		)
+	}
+}
+
+// Draw the per-level breakdown centered at centerX
+func DrawLevelStats(gs *GameStats, centerX, y int32) {
+	columns := []int32{0, 120, 240}
+	left := centerX - 190
+	drawTableRow(left, columns, y, rl.Gray, "LEVEL", "KILLS", "DAMAGE TAKEN")
+
+	for i, ls := range gs.Levels() {
+		drawTableRow(left, columns, y+int32(i+1)*30, rl.White,
+			fmt.Sprintf("%d", ls.Level),
+			fmt.Sprintf("%d", ls.Kills),
+			fmt.Sprintf("%.0f", ls.DamageTaken),
+		)
+	}
+}
+
+// The default font isn't monospaced, so every column gets its own x
+func drawTableRow(left int32, columns []int32, y int32, color rl.Color, cells ...string) {
+	for i, cell := range cells {
+		rl.DrawText(cell, left+columns[i], y, 20, color)
+	}
+}
+
+// Keep the counters up to date from gameplay events
+func subscribeStats(bus *EventBus) {
+	Subscribe(bus, func(e ShotFired) {
+		gameStats.RecordShots(e.Weapon, e.Count)
+	})
+	Subscribe(bus, func(e GrenadeThrown) {
+		// Grenades never count as hits, so they're kept out of the overall accuracy
+		gameStats.grenadesThrown++
+		gameStats.weapon(GRENADE_WEAPON_NAME).Shots++
+	})
+	Subscribe(bus, func(e EnemyHit) {
+		if e.Hit {
+			gameStats.RecordHit(e.Weapon, e.Damage)
+		} else {
+			gameStats.RecordDamage(e.Weapon, e.Damage)
+		}
+	})
+	Subscribe(bus, func(e EnemyKilled) {
+		gameStats.RecordKill(e.Weapon, e.Level)
+	})
+	Subscribe(bus, func(e DamageTaken) {
+		gameStats.RecordDamageTaken(e.Level, e.Amount)
+	})
+	Subscribe(bus, func(e PickupCollected) {
+		gameStats.RecordPickup(e.Kind)
+	})
+	Subscribe(bus, func(e ReloadStarted) {
+		gameStats.RecordReload(e.Weapon)
+	})
+	Subscribe(bus, func(e LevelCompleted) {
+		gameStats.levelReached = e.Level + 1
+	})
+}
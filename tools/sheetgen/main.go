@@ -0,0 +1,261 @@
+// Command sheetgen builds the animated sprite sheets from the static sprites in assets/.
+//
+// Every clip is made by posing the static sprite frame by frame (bobbing, leaning,
+// squashing, flashing and falling over), laid out the way playerSheetDef and
+// zombieSheetDef in animation.go expect. Run it from the repository root:
+//
+//	go run ./tools/sheetgen
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"math"
+	"os"
+)
+
+const (
+	frameSize    = 64 // Width and height of one frame
+	spriteHeight = 60 // Height of the sprite standing in a frame
+	supersample  = 4  // Samples per side of every output pixel
+)
+
+// pose is how the sprite is moved and colored in one frame
+type pose struct {
+	dx, dy  float64     // Offset of the sprite center in pixels
+	angle   float64     // Rotation around the sprite center in degrees, clockwise
+	sx, sy  float64     // Scale, 0 counts as 1
+	mix     float64     // How much of mixWith is blended in
+	mixWith color.NRGBA // Color blended in, for flashes
+	plant   bool        // Keep the feet on the ground when squashing
+	lie     float64     // How far the sprite has fallen over, from 0 to 1, moves it down to the ground
+}
+
+type clip []pose
+
+var (
+	red   = color.NRGBA{R: 220, G: 20, B: 20, A: 255}
+	white = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	black = color.NRGBA{A: 255}
+)
+
+// Rows of the player sheet, in the order of playerSheetDef
+func playerClips() []clip {
+	return []clip{
+		// Idle: breathing
+		{{sy: 1, plant: true}, {sx: 1.01, sy: 0.98, plant: true}, {sx: 1.02, sy: 0.97, plant: true}, {sx: 1.01, sy: 0.98, plant: true}},
+		// Walk: bob and sway
+		{{dy: 0, angle: -3}, {dy: -3, angle: 0}, {dy: -1, angle: 3}, {dy: 0, angle: 3}, {dy: -3, angle: 0}, {dy: -1, angle: -3}},
+		// Shoot: recoil, a flash on the first frame
+		{{dx: -3, angle: -4, mix: 0.25, mixWith: white}, {dx: -2, angle: -2}, {dx: -1, angle: -1}},
+		// Reload: leaning over the gun
+		{{dy: 1, angle: 4}, {dy: 2, angle: 6}, {dy: 2, angle: 7}, {dy: 2, angle: 7}, {dy: 2, angle: 6}, {dy: 1, angle: 4}},
+		// Hurt: red flash and a flinch
+		{{dx: -2, mix: 0.6, mixWith: red}, {dx: -1, mix: 0.3, mixWith: red}},
+		// Death: falling backwards
+		fall(6, -90, 0.35),
+	}
+}
+
+// Rows of the zombie sheet, in the order of zombieSheetDef
+func zombieClips() []clip {
+	return []clip{
+		// Idle: swaying on the spot
+		{{angle: -2}, {angle: 0}, {angle: 2}, {angle: 0}},
+		// Walk: shambling
+		{{dy: 0, angle: -5}, {dy: -2, angle: -2}, {dy: -1, angle: 2}, {dy: 0, angle: 5}, {dy: -2, angle: 2}, {dy: -1, angle: -2}},
+		// Hurt: white flash and a flinch
+		{{dx: -2, mix: 0.7, mixWith: white}, {dx: -1, mix: 0.3, mixWith: white}},
+		// Death: falling forwards
+		fall(5, 90, 0.4),
+	}
+}
+
+// Frames of a sprite falling over to angle, darkening a little
+func fall(frames int, angle, darken float64) clip {
+	c := make(clip, frames)
+	for i := range c {
+		t := float64(i) / float64(frames-1)
+		t = 1 - (1-t)*(1-t) // Ease out, it hits the ground fast and settles
+		c[i] = pose{angle: angle * t, lie: t, mix: darken * t, mixWith: black}
+	}
+	return c
+}
+
+func main() {
+	build("assets/player_right.png", false, playerClips(), "assets/player_sheet.png")
+	build("assets/player_top.png", true, playerClips(), "assets/player_front_sheet.png")
+	build("assets/zombie.png", false, zombieClips(), "assets/zombie_sheet.png")
+}
+
+// Pose the sprite at src for every frame of clips and save the sheet to dst
+func build(src string, keyBackground bool, clips []clip, dst string) {
+	sprite := load(src)
+	if keyBackground {
+		removeBackground(sprite)
+	}
+	sprite = trim(sprite)
+
+	columns := 0
+	for _, c := range clips {
+		if len(c) > columns {
+			columns = len(c)
+		}
+	}
+
+	sheet := image.NewNRGBA(image.Rect(0, 0, columns*frameSize, len(clips)*frameSize))
+	for row, c := range clips {
+		for col, p := range c {
+			drawFrame(sheet, sprite, col*frameSize, row*frameSize, p)
+		}
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer out.Close()
+	if err := png.Encode(out, sheet); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%s -> %s (%dx%d)", src, dst, sheet.Bounds().Dx(), sheet.Bounds().Dy())
+}
+
+func load(path string) *image.NRGBA {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		log.Fatalf("%s: %s", path, err)
+	}
+
+	b := img.Bounds()
+	out := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			out.Set(x, y, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return out
+}
+
+// Make the flat background around an opaque sprite transparent, filling in from the edges
+func removeBackground(img *image.NRGBA) {
+	b := img.Bounds()
+	bg := img.NRGBAAt(0, 0)
+	close := func(c color.NRGBA) bool {
+		d := math.Abs(float64(c.R)-float64(bg.R)) + math.Abs(float64(c.G)-float64(bg.G)) + math.Abs(float64(c.B)-float64(bg.B))
+		return d < 40
+	}
+
+	var stack []image.Point
+	for x := 0; x < b.Dx(); x++ {
+		stack = append(stack, image.Pt(x, 0), image.Pt(x, b.Dy()-1))
+	}
+	for y := 0; y < b.Dy(); y++ {
+		stack = append(stack, image.Pt(0, y), image.Pt(b.Dx()-1, y))
+	}
+
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !p.In(b) {
+			continue
+		}
+		c := img.NRGBAAt(p.X, p.Y)
+		if c.A == 0 || !close(c) {
+			continue
+		}
+		img.SetNRGBA(p.X, p.Y, color.NRGBA{})
+		stack = append(stack, image.Pt(p.X+1, p.Y), image.Pt(p.X-1, p.Y), image.Pt(p.X, p.Y+1), image.Pt(p.X, p.Y-1))
+	}
+}
+
+// Cut the image down to the part that isn't transparent
+func trim(img *image.NRGBA) *image.NRGBA {
+	b := img.Bounds()
+	box := image.Rectangle{Min: b.Max, Max: b.Min}
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			if img.NRGBAAt(x, y).A > 32 {
+				box = box.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return img.SubImage(box).(*image.NRGBA)
+}
+
+// Draw the posed sprite into the frame at (fx, fy)
+func drawFrame(sheet, sprite *image.NRGBA, fx, fy int, p pose) {
+	sb := sprite.Bounds()
+	srcW, srcH := float64(sb.Dx()), float64(sb.Dy())
+	scale := spriteHeight / srcH
+
+	sx, sy := p.sx, p.sy
+	if sx == 0 {
+		sx = 1
+	}
+	if sy == 0 {
+		sy = 1
+	}
+
+	// Center of the sprite in the frame, standing with its feet near the bottom
+	cx := frameSize/2 + p.dx
+	cy := frameSize/2 + p.dy
+	if p.plant {
+		cy += (1 - sy) * spriteHeight / 2
+	}
+	// Lying down, the sprite's width is its height above the ground
+	cy += p.lie * (spriteHeight - srcW*scale) / 2
+
+	sin, cos := math.Sincos(-p.angle * math.Pi / 180)
+
+	for y := 0; y < frameSize; y++ {
+		for x := 0; x < frameSize; x++ {
+			var r, g, bl, a float64
+			for sy2 := 0; sy2 < supersample; sy2++ {
+				for sx2 := 0; sx2 < supersample; sx2++ {
+					// Undo the pose to find where the sample comes from in the sprite
+					px := float64(x) + (float64(sx2)+0.5)/supersample - cx
+					py := float64(y) + (float64(sy2)+0.5)/supersample - cy
+					u := (px*cos - py*sin) / (scale * sx)
+					v := (px*sin + py*cos) / (scale * sy)
+
+					ix := int(math.Floor(u + srcW/2))
+					iy := int(math.Floor(v + srcH/2))
+					if ix < 0 || iy < 0 || ix >= sb.Dx() || iy >= sb.Dy() {
+						continue
+					}
+					c := sprite.NRGBAAt(sb.Min.X+ix, sb.Min.Y+iy)
+					ca := float64(c.A) / 255
+					r += float64(c.R) * ca
+					g += float64(c.G) * ca
+					bl += float64(c.B) * ca
+					a += ca
+				}
+			}
+			if a == 0 {
+				continue
+			}
+
+			// Average the samples, then blend in the flash color
+			r, g, bl = r/a, g/a, bl/a
+			r += (float64(p.mixWith.R) - r) * p.mix
+			g += (float64(p.mixWith.G) - g) * p.mix
+			bl += (float64(p.mixWith.B) - bl) * p.mix
+			alpha := a / (supersample * supersample)
+
+			sheet.SetNRGBA(fx+x, fy+y, color.NRGBA{
+				R: uint8(math.Round(r)),
+				G: uint8(math.Round(g)),
+				B: uint8(math.Round(bl)),
+				A: uint8(math.Round(alpha * 255)),
+			})
+		}
+	}
+}